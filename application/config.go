@@ -3,14 +3,19 @@ package application
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
 	"forum/controllers/chat"
+	"forum/controllers/mailer"
+	"forum/controllers/oauth"
+	"forum/controllers/ratelimit"
 	"forum/logger"
 	"forum/model"
 	"forum/model/sqlpkg"
 	"forum/view"
+	"forum/wsmodel"
 
 	"github.com/gorilla/websocket"
 	"golang.org/x/crypto/bcrypt"
@@ -26,10 +31,57 @@ var (
 		Gender:    "They",
 		FirstName: "AD",
 		LastName:  "MIN",
+		Role:      model.ROLE_ADMIN,
 	}
 	ADM_PASS = "admin"
 )
 
+// the default lifetimes of the sessions
+const (
+	SESSION_ABSOLUTE_TIMEOUT = 24 * time.Hour
+	SESSION_REMEMBER_TIMEOUT = 30 * 24 * time.Hour
+	SESSION_IDLE_TIMEOUT     = 2 * time.Hour
+	SESSION_CHECK_PERIOD     = time.Minute
+)
+
+// SessionConfig keeps the lifetimes of the users' sessions
+type SessionConfig struct {
+	AbsoluteTimeout time.Duration // a session expires after the time since the login
+	RememberTimeout time.Duration // the absolute timeout of the sessions created with "remember me"
+	IdleTimeout     time.Duration // a session expires if it is not used during the time, the remembered sessions don't
+	CheckPeriod     time.Duration // the open websocket connections check their sessions with the period
+}
+
+// the budgets of the requests of a user, or of an IP address for an anonymous user
+var (
+	WS_DEFAULT_BUDGET = ratelimit.Budget{Burst: 60, Period: 10 * time.Second}
+	WS_BUDGETS        = map[string]ratelimit.Budget{
+		wsmodel.NewPostRequest:                {Burst: 3, Period: time.Minute},
+		wsmodel.NewCommentRequest:             {Burst: 10, Period: time.Minute},
+		wsmodel.SendMessageToOpendChatRequest: {Burst: 20, Period: 20 * time.Second},
+		wsmodel.SendMessageToGroupChatRequest: {Burst: 20, Period: 20 * time.Second},
+		wsmodel.RegisterRequest:               {Burst: 5, Period: time.Hour},
+		wsmodel.LoginRequest:                  {Burst: 10, Period: time.Minute},
+		wsmodel.LoginSecondFactorRequest:      {Burst: 10, Period: time.Minute},
+		wsmodel.ReportRequest:                 {Burst: 5, Period: time.Minute},
+		wsmodel.SendVerificationEmailRequest:  {Burst: 3, Period: time.Hour},
+	}
+	HTTP_DEFAULT_BUDGET = ratelimit.Budget{Burst: 30, Period: time.Minute}
+	HTTP_BUDGETS        = map[string]ratelimit.Budget{
+		"ws":            {Burst: 10, Period: time.Minute},
+		"upload":        {Burst: 10, Period: time.Minute},
+		"passwordReset": {Burst: 5, Period: 15 * time.Minute},
+		"oauth":         {Burst: 10, Period: time.Minute},
+	}
+)
+
+const (
+	// the time in which the rejected requests of a user are counted
+	RATE_LIMIT_VIOLATION_WINDOW = time.Minute
+	// a websocket connection is closed after the number of rejected messages during the window
+	RATE_LIMIT_MAX_VIOLATIONS = 20
+)
+
 type Application struct {
 	ErrLog    *log.Logger
 	InfoLog   *log.Logger
@@ -38,6 +90,17 @@ type Application struct {
 	ForumData *sqlpkg.ForumModel
 	Upgrader  websocket.Upgrader
 	Server    *http.Server
+	Mailer    mailer.Mailer
+	BaseURL   string // the address of the forum used in the links sent to the users
+	// the configured providers for the login with OAuth2 by their names and the logins started with them
+	OAuthProviders map[string]*oauth.Provider
+	OAuthStates    *oauth.States
+	Session        SessionConfig
+	// the reverse proxies in front of the forum, the client's IP address is taken from their X-Forwarded-For header
+	TrustedProxies []*net.IPNet
+	// the limits of the websocket messages by their types and of the HTTP requests by the kinds of the routes
+	WSLimiter   *ratelimit.Limiter
+	HTTPLimiter *ratelimit.Limiter
 }
 
 func New() (*Application, error) {
@@ -55,6 +118,22 @@ func New() (*Application, error) {
 
 	application.InfoLog.Println("The chat Hub is created")
 
+	// the emails are written to the info log, until an SMTP server is configured
+	application.Mailer = &mailer.LogMailer{Log: application.InfoLog}
+
+	application.OAuthProviders = make(map[string]*oauth.Provider)
+	application.OAuthStates = oauth.NewStates()
+
+	application.Session = SessionConfig{
+		AbsoluteTimeout: SESSION_ABSOLUTE_TIMEOUT,
+		RememberTimeout: SESSION_REMEMBER_TIMEOUT,
+		IdleTimeout:     SESSION_IDLE_TIMEOUT,
+		CheckPeriod:     SESSION_CHECK_PERIOD,
+	}
+
+	application.WSLimiter = ratelimit.New(WS_DEFAULT_BUDGET, WS_BUDGETS, RATE_LIMIT_VIOLATION_WINDOW)
+	application.HTTPLimiter = ratelimit.New(HTTP_DEFAULT_BUDGET, HTTP_BUDGETS, RATE_LIMIT_VIOLATION_WINDOW)
+
 	application.Upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
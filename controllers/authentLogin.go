@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/mail"
+	"time"
 
 	"forum/application"
 	"forum/model"
@@ -42,19 +43,44 @@ func replyLogin(app *application.Application, w http.ResponseWriter, currConnect
 		return err
 	}
 
-	newSess, err := session.New(app, w, user)
+	// the session is created after the second step for the users with two-factor authentication
+	twoFactorEnabled, err := isTwoFactorEnabled(app, user.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "getting the second factor of the user from DB failed", err)
+	}
+	if twoFactorEnabled {
+		return requestSecondFactor(app, currConnection, user, userCredentials.RememberMe)
+	}
+
+	return completeLogin(app, w, currConnection, message, user, userCredentials.RememberMe)
+}
+
+/*
+creates the session of the user, who passed all the login steps, and replies with the session data
+*/
+func completeLogin(app *application.Application, w http.ResponseWriter, currConnection *usersConnection, message wsmodel.WSMessage, user *model.User, remember bool) error {
+	err := app.ForumData.DeleteLoginFailures(user.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "deleting the failed logins from DB failed", err)
+	}
+
+	newSess, err := session.New(app, w, user, currConnection.device, remember)
 	if err != nil {
 		return errHelper(app, currConnection, "session creation failed", err)
 	}
 
-	
-	err = sendReply(app, currConnection, message, newSess)
+	sessionData, err := createSessionData(app, currConnection, newSess)
+	if err != nil {
+		return err
+	}
+
+	err = sendReply(app, currConnection, message, sessionData)
 	if err != nil {
 		return err
 	}
-	
-	app.InfoLog.Printf("User '%v' logged in", userCredentials.Username)
-	
+
+	app.InfoLog.Printf("User '%v' logged in", user.Name)
+
 	currConnection.session = newSess
 	return currConnection.renewClientForUser(app, newSess)
 }
@@ -69,9 +95,18 @@ func validateCredentials(app *application.Application, currConnection *usersConn
 	return nil
 }
 
+/*
+returns the user with the given credentials. The attempts are throttled by the account and by the IP address of the connection,
+the failed attempts are kept in DB
+*/
 func getUserFromDB(app *application.Application, currConnection *usersConnection, userCredentials wsmodel.UserCredentials, message wsmodel.WSMessage) (*model.User, error) {
 	var user *model.User
 
+	err := checkLoginAllowedFromIP(app, currConnection, message)
+	if err != nil {
+		return nil, err
+	}
+
 	address, err := mail.ParseAddress(userCredentials.Username)
 	if err == nil {
 		user, err = app.ForumData.GetUserByEmail(address.Address)
@@ -82,16 +117,37 @@ func getUserFromDB(app *application.Application, currConnection *usersConnection
 	// Did the given user exist in DB?
 	if err != nil {
 		if err == model.ErrNoRecord {
+			err = registerLoginFailure(app, currConnection, 0)
+			if err != nil {
+				return nil, err
+			}
 			return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("User '%s' doesn't exist", userCredentials.Username))
 		}
 		return nil, errHelper(app, currConnection, fmt.Sprintf("get the user '%s' from DB failed", userCredentials.Username), err)
 	}
 
+	// the password is not checked during the delay, so it cannot be guessed faster
+	err = checkLoginAllowedToAccount(app, currConnection, user, message)
+	if err != nil {
+		return nil, err
+	}
+
 	// Does the password match?
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userCredentials.Password)); err != nil {
+		err = registerLoginFailure(app, currConnection, user.ID)
+		if err != nil {
+			return nil, err
+		}
 		return nil, badRequestHelper(app, currConnection, message, "Wrong password")
 	}
 
+	if user.Banned {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("User '%s' is banned", userCredentials.Username))
+	}
+	if user.IsSuspended(time.Now()) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("User '%s' is suspended until %s", userCredentials.Username, user.SuspendedUntil.Format(time.RFC3339)))
+	}
+
 	return user, nil
 }
 
@@ -41,12 +41,23 @@ func replyRegister(app *application.Application, w http.ResponseWriter, currConn
 		return err
 	}
 
-	newSess, err := session.New(app, w, user)
+	// the user can log in without the verification, so a failed email is only logged, the user can request it again
+	err = sendVerificationEmail(app, user)
+	if err != nil {
+		app.ErrLog.Printf("sending the verification email to the user '%s' failed: %v", user.Name, err)
+	}
+
+	newSess, err := session.New(app, w, user, currConnection.device, false)
 	if err != nil {
 		return errHelper(app, currConnection, "session creation failed", err)
 	}
 
-	err = sendReply(app, currConnection, message, newSess)
+	sessionData, err := createSessionData(app, currConnection, newSess)
+	if err != nil {
+		return err
+	}
+
+	err = sendReply(app, currConnection, message, sessionData)
 	if err != nil {
 		return err
 	}
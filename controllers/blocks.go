@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+blocks the user with the id given in the payload: the users cannot chat,
+the blocked user is hidden from the current user's online list and the posts and comments of the blocked user are hidden.
+The blocked user is removed from the online list on the other devices of the current user
+*/
+func replyBlockUser(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	userID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || userID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for blocking a user: '%s'", message.Payload), err)
+	}
+	if userID == currConnection.session.User.ID {
+		return nil, badRequestHelper(app, currConnection, message, "the user cannot block themselves")
+	}
+
+	user, err := getExistingUser(app, currConnection, userID, message)
+	if err != nil {
+		return nil, err
+	}
+	blockedUser := &model.User{ID: user.ID, Name: user.Name}
+
+	err = app.ForumData.BlockUser(currConnection.session.User.ID, userID, time.Now())
+	if errors.Is(err, model.ErrUnique) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the user %d is blocked already", userID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "blocking the user in DB failed", err)
+	}
+	app.InfoLog.Printf("user %s is blocked by %s", blockedUser, currConnection.session.User)
+
+	err = sendMessageToUser(app, currConnection, currConnection.session.User.ID, wsmodel.OfflineUser, blockedUser)
+	if err != nil {
+		return nil, err
+	}
+	return blockedUser, nil
+}
+
+/*
+unblocks the user with the id given in the payload
+*/
+func replyUnblockUser(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	userID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || userID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for unblocking a user: '%s'", message.Payload), err)
+	}
+
+	err = app.ForumData.UnblockUser(currConnection.session.User.ID, userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the user %d is not blocked", userID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "unblocking the user in DB failed", err)
+	}
+	app.InfoLog.Printf("user %d is unblocked by %s", userID, currConnection.session.User)
+
+	return userID, nil
+}
+
+/*
+replies with the list of the users blocked by the current user
+*/
+func replyBlockedUsers(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	blockedUsersID, err := getBlockedUsersID(app, currConnection)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := app.ForumData.GetFilteredUsers(blockedUsersID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the blocked users from DB failed", err)
+	}
+
+	blockedUsers := make([]*model.User, 0, len(users))
+	for _, user := range users {
+		blockedUsers = append(blockedUsers, &model.User{ID: user.ID, Name: user.Name})
+	}
+	return blockedUsers, nil
+}
+
+/*
+returns nil if neither of the current user and the user 'userID' blocked the other one,
+otherwise sends an error message and returns an error
+*/
+func checkNotBlocked(app *application.Application, currConnection *usersConnection, userID int, message wsmodel.WSMessage) error {
+	blocked, err := app.ForumData.IsBlockedBetween(currConnection.session.User.ID, userID)
+	if err != nil {
+		return errHelper(app, currConnection, "checking the blocks of the users in DB failed", err)
+	}
+	if blocked {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat with the user %d is blocked", userID))
+	}
+	return nil
+}
+
+func getBlockedUsersID(app *application.Application, currConnection *usersConnection) (model.IDSet, error) {
+	blockedUsersID, err := app.ForumData.GetBlockedUsersID(currConnection.session.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the blocked users from DB failed", err)
+	}
+	return blockedUsersID, nil
+}
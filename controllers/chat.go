@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"forum/application"
+	"forum/controllers/chat"
 	"forum/model"
 	"forum/wsmodel"
 	"forum/wsmodel/parse"
@@ -16,41 +17,74 @@ func replyOpenChat(app *application.Application, currConnection *usersConnection
 		return nil, errHelper(app, currConnection, fmt.Sprintf("Can't open a chat: invalid userID '%s'", message.Payload), err)
 	}
 
-	// check if the user is still online
-	userClient, ok := app.Hub.GetUsersClient(userID)
-	if !ok {
-		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d is offline", userID))
+	recipient, err := getChatRecipient(app, currConnection, userID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkNotBlocked(app, currConnection, recipient.ID, message)
+	if err != nil {
+		return nil, err
 	}
 
-	chat, err := getChatHistory(app, currConnection, userClient.User.ID, message)
+	privateChat, err := getChatHistory(app, currConnection, recipient.ID, message)
 	if err != nil {
 		return nil, err
 	}
 
-	currConnection.Client.OpenedChatWith.ChatID = chat.ID
-	currConnection.Client.OpenedChatWith.ChatName = chat.Name
-	currConnection.Client.OpenedChatWith.UserClient = userClient
+	// the user is going to see the last messages, so all of them are read
+	err = app.ForumData.MarkChatAsRead(privateChat.ID, currConnection.Client.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "marking the chat messages as read failed", err)
+	}
+
+	currConnection.Client.OpenedChats.Open(chat.OpenedChat{
+		ChatID:   privateChat.ID,
+		ChatName: privateChat.Name,
+		Type:     model.PRIVATE_CHAT,
+		User:     recipient,
+	})
 
-	app.InfoLog.Printf("Chat with '%s' is opened.", currConnection.Client.OpenedChatWith.UserClient.User.Name)
+	app.InfoLog.Printf("Chat with '%s' is opened.", recipient.Name)
 
-	return createPrivateChatForReply(chat, currConnection.Client.User, currConnection.Client.OpenedChatWith.UserClient.User), nil
+	return createPrivateChatForReply(privateChat, currConnection.Client.User, recipient), nil
 }
 
+/*
+closes the chat with the chat id given in the payload. If there is no chat id, all the opened chats are closed
+*/
 func replyCloseChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
-	err := checkLoggedStatus(app, currConnection, message)
+	if len(message.Payload) == 0 || string(message.Payload) == "null" {
+		err := stopAllTyping(app, currConnection)
+		if err != nil {
+			return nil, err
+		}
+		currConnection.Client.OpenedChats.CloseAll()
+		app.InfoLog.Printf("All the chats of '%s' are closed.", currConnection.Client.User)
+		return nil, nil
+	}
+
+	chatID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || chatID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Can't close a chat: invalid chatID '%s'", message.Payload), err)
+	}
+
+	err = stopTyping(app, currConnection, chatID)
 	if err != nil {
 		return nil, err
 	}
+	if !currConnection.Client.OpenedChats.Close(chatID) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat %d is not opened", chatID))
+	}
+	app.InfoLog.Printf("Chat %d is closed by '%s'.", chatID, currConnection.Client.User)
 
-	app.InfoLog.Printf("Chat with '%s' is closed.", currConnection.Client.OpenedChatWith.UserClient.User.Name)
-	currConnection.Client.OpenedChatWith.ChatID = 0
-	currConnection.Client.OpenedChatWith.UserClient = nil
-
-	return nil, nil
+	return chatID, nil
 }
 
+/*
+sends the message to the opened chat with the chat id given in the payload
+*/
 func replySendMessageToOpendChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
-	
 	chatMessage, err := parse.PayloadToChatMessage(message.Payload)
 	if err != nil {
 		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a chat message: '%s'", message.Payload), err)
@@ -61,54 +95,105 @@ func replySendMessageToOpendChat(app *application.Application, currConnection *u
 		return nil, badRequestHelper(app, currConnection, message, errmessage)
 	}
 
-	id, err := app.ForumData.InsertChatMessage(currConnection.Client.OpenedChatWith.ChatID, currConnection.session.User.ID, chatMessage.MessageContent, nil, chatMessage.Date)
+	err = checkAttachedImages(app, currConnection, message, chatMessage.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	openedChat, ok := currConnection.Client.OpenedChats.Get(chatMessage.ChatID)
+	if !ok {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat %d is not opened", chatMessage.ChatID))
+	}
+	if openedChat.Type == model.GROUP_CHAT {
+		return sendMessageToGroupChat(app, currConnection, chatMessage, message)
+	}
+
+	err = checkNotBlocked(app, currConnection, openedChat.User.ID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := app.ForumData.InsertChatMessage(openedChat.ChatID, currConnection.session.User.ID, chatMessage.MessageContent, chatMessage.Images, chatMessage.Date)
 	if err != nil {
 		return nil, errHelper(app, currConnection, fmt.Sprintf("save chat message in DB failed: '%s'", chatMessage.MessageContent), err)
 	}
+	chatMessage.ID = id
+	chatMessage.Author = currConnection.Client.User
 
-	// check if the user is still online
-	if !app.Hub.IsThereClient(currConnection.Client.OpenedChatWith.UserClient) {
-		var ok bool
-		currConnection.Client.OpenedChatWith.UserClient, ok = app.Hub.GetUsersClient(currConnection.Client.OpenedChatWith.UserClient.User.ID)
-		if !ok {
-			errDel := app.ForumData.DeleteChatMessage(id)
-			if errDel != nil {
-				app.ErrLog.Printf("DeleteChatMessage failed: %v\n", err)
-			}
-			return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d is offline", currConnection.Client.OpenedChatWith.UserClient.User.ID))
-		}
+	err = stopTyping(app, currConnection, chatMessage.ChatID)
+	if err != nil {
+		return nil, err
 	}
 
-	err = sendMessageToRecipient(app, currConnection, chatMessage)
+	// the message is shown on the other devices of the author too
+	err = sendMessageToUser(app, currConnection, currConnection.session.User.ID, wsmodel.InputChatMessage, chatMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	// the reply contains the message's id, so the author can match the message with receipts 'messageDelivered' and 'messageRead'.
+	// The message is kept in DB as unread, so if the user is offline, it will be delivered when the user comes online
+	if !app.Hub.IsUserOnline(openedChat.User.ID) {
+		app.InfoLog.Printf("user '%s' is offline, the chat message %d is saved to be delivered later", openedChat.User, id)
+		return chatMessage, nil
+	}
+
+	err = sendMessageToUser(app, currConnection, openedChat.User.ID, wsmodel.InputChatMessage, chatMessage)
+	if err != nil {
+		return nil, err
+	}
+	err = setChatMessageDelivered(app, currConnection, chatMessage, openedChat.User.ID)
 	if err != nil {
-		errDel := app.ForumData.DeleteChatMessage(id)
-		if errDel != nil {
-			app.ErrLog.Printf("DeleteChatMessage failed: %v\n", err)
-		}
 		return nil, err
 	}
 
-	return "delivered", nil
+	err = setReadIfChatOpened(app, currConnection, openedChat.User.ID, chatMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return chatMessage, nil
 }
 
 func replyChatPortion(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
-	
-	beforeID, err := parse.PayloadToInt(message.Payload)
+	portion, err := parse.PayloadToChatPortion(message.Payload)
 	if err != nil {
 		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for the portion of messages '%s'", message.Payload), err)
 	}
 
-	chat, err := app.ForumData.GetPrivateChatMessagesByChatId(currConnection.Client.OpenedChatWith.ChatID, beforeID, CHAT_MESSAGES_PORTION)
+	errmessage := portion.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	openedChat, ok := currConnection.Client.OpenedChats.Get(portion.ChatID)
+	if !ok {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat %d is not opened", portion.ChatID))
+	}
+
+	if openedChat.Type == model.GROUP_CHAT {
+		groupChat, err := getGroupChatOfMember(app, currConnection, openedChat.ChatID, message)
+		if err != nil {
+			return nil, err
+		}
+		err = addGroupChatMessages(app, currConnection, groupChat, portion.BeforeID)
+		if err != nil {
+			return nil, err
+		}
+		return groupChat, nil
+	}
+
+	privateChat, err := app.ForumData.GetPrivateChatMessagesByChatId(openedChat.ChatID, portion.BeforeID, CHAT_MESSAGES_PORTION)
 	if err != nil {
 		return nil, errHelper(app, currConnection, "get the next portion of chat messages from DB failed", err)
 	}
 
-	chat.ID = currConnection.Client.OpenedChatWith.ChatID
-	chat.Name = currConnection.Client.OpenedChatWith.ChatName
+	privateChat.ID = openedChat.ChatID
+	privateChat.Name = openedChat.ChatName
 
-	app.InfoLog.Printf("Send the next portion of messages to chat '%s-%s'.", currConnection.Client.OpenedChatWith.UserClient.User.Name, currConnection.Client.User.Name)
-	
-	return createPrivateChatForReply(chat, currConnection.Client.User, currConnection.Client.OpenedChatWith.UserClient.User),nil
+	app.InfoLog.Printf("Send the next portion of messages to chat '%s-%s'.", openedChat.User.Name, currConnection.Client.User.Name)
+
+	return createPrivateChatForReply(privateChat, currConnection.Client.User, openedChat.User), nil
 }
 
 /*
@@ -153,13 +238,78 @@ func createChat(app *application.Application, currConnection *usersConnection, u
 }
 
 /*
-sends a chat message to the user stored in OpenedChatWith
-used in replySendMessageToOpendChat
+returns the user with the given id to open a chat with. The user may be offline.
+Used in replyOpenChat function
 */
-func sendMessageToRecipient(app *application.Application, currConnection *usersConnection, chatMessage wsmodel.ChatMessage) error {
-	recipientConnection := &usersConnection{Client: currConnection.Client.OpenedChatWith.UserClient}
-	chatMessage.Author = currConnection.Client.User
-	return sendSuccessMessage(app, recipientConnection, wsmodel.InputChatMessage, chatMessage)
+func getChatRecipient(app *application.Application, currConnection *usersConnection, userID int, message wsmodel.WSMessage) (*model.User, error) {
+	if userClients := app.Hub.GetUsersClients(userID); len(userClients) != 0 {
+		return userClients[0].User, nil
+	}
+
+	user, err := app.ForumData.GetUserByID(userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d doesn't exist", userID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the user with id %d from DB failed", userID), err)
+	}
+
+	return &model.User{ID: user.ID, Name: user.Name}, nil
+}
+
+/*
+marks the chat message as read by the recipient if the recipient has the chat opened on any device,
+because the message is shown in the recipient's chat window
+*/
+func setReadIfChatOpened(app *application.Application, currConnection *usersConnection, recipientID int, chatMessage wsmodel.ChatMessage) error {
+	for _, client := range app.Hub.GetUsersClients(recipientID) {
+		if !client.OpenedChats.IsOpened(chatMessage.ChatID) {
+			continue
+		}
+
+		err := app.ForumData.SetLastReadChatMessage(chatMessage.ChatID, recipientID, chatMessage.ID)
+		if err != nil {
+			return errHelper(app, currConnection, "marking the chat message as read failed", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+/*
+sends to the current user the last unread chat messages (maximum CHAT_BACKLOG_LIMIT)
+which were sent while the user was offline or hadn't opened the chat. The messages are marked as delivered,
+so they are not sent again on the next connection or to another device of the user
+*/
+func sendUnreadChatMessages(app *application.Application, currConnection *usersConnection) error {
+	messages, err := app.ForumData.GetUndeliveredChatMessages(currConnection.session.User.ID, CHAT_BACKLOG_LIMIT)
+	if err != nil {
+		return errHelper(app, currConnection, "get unread chat messages from DB failed", err)
+	}
+
+	for _, m := range messages {
+		chatMessage := wsmodel.ChatMessage{
+			ID:             m.ID,
+			ChatID:         m.ChatID,
+			MessageContent: m.Content,
+			Author:         m.Author,
+			Date:           m.DateCreate,
+			Images:         m.Images,
+		}
+		err = sendSuccessMessage(app, currConnection, wsmodel.InputChatMessage, chatMessage)
+		if err != nil {
+			return err
+		}
+		err = setChatMessageDelivered(app, currConnection, chatMessage, currConnection.session.User.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(messages) != 0 {
+		app.InfoLog.Printf("%d unread chat messages are sent to the user %s", len(messages), currConnection.session.User)
+	}
+	return nil
 }
 
 func createPrivateChatForReply(chat *model.Chat, currentUser, recipientUser *model.User) wsmodel.PrivatChat {
@@ -1,16 +1,25 @@
 package chat
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"forum/model"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	closeWait            = time.Second // time allowed to write the close frame to the peer
+	maxCloseReasonLength = 123         // the payload of a control frame is limited by 125 bytes, 2 of them are the close code
+)
+
 // Client is a middleman between the websocket connection and the hub.
 type Client struct {
 	User *model.User
+	// the uuid of the session of the logged in user, empty for not logged in users
+	SessionUUID string
 
 	// The websocket connection.
 	Conn *websocket.Conn
@@ -20,21 +29,28 @@ type Client struct {
 	ClientRegistered chan struct{}
 	// OnlineUsers      chan  MapID
 
-	OpenedChatWith struct {
-		ChatID     int
-		ChatName   string
-		UserClient *Client
-	}
+	// the chats shown in the chat windows of the client
+	OpenedChats *SafeOpenedChats
+	// the chats where the client's user is typing
+	Typing *SafeTypingTimers
+	// the post shown in the full post view of the client
+	ViewedPost *SafeViewedPost
 }
 
 func NewClient(hub *Hub, user *model.User, conn *websocket.Conn, receivedMessages chan []byte, clientRegistered chan struct{}) *Client {
 	var shortUser *model.User
+	var sessionUUID string
 	if user != nil {
 		shortUser = &model.User{ID: user.ID, Name: user.Name}
+		sessionUUID = user.Uuid
 	}
 	client := &Client{
-		User: shortUser,
-		Conn: conn,
+		User:        shortUser,
+		SessionUUID: sessionUUID,
+		Conn:        conn,
+		OpenedChats: NewSafeOpenedChats(),
+		Typing:      NewSafeTypingTimers(),
+		ViewedPost:  &SafeViewedPost{},
 		// OnlineUsers:      make(chan  MapID),
 	}
 
@@ -58,6 +74,20 @@ func NewClient(hub *Hub, user *model.User, conn *websocket.Conn, receivedMessage
 
 
 
+/*
+Disconnect sends the close frame with the reason to the peer and closes the connection of the client.
+The reading of the connection fails then and ReadPump unregisters the client.
+It is safe to call Disconnect concurrently with the reading and the writing of the connection
+*/
+func (c *Client) Disconnect(reason string) error {
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+	closeMessage := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+	errWrite := c.Conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(closeWait))
+	return errors.Join(errWrite, c.Conn.Close())
+}
+
 func (c *Client) WriteMessage(message []byte) {
 	c.ReceivedMessages <- message
 }
@@ -42,11 +42,72 @@ func (sm *SafeClientsMap) RRange(act func(key *Client, value bool)) {
 	}
 }
 
+// SafeUsersClients indexes the clients of logged in users by the users' ids.
+// A user has a client for each device (browser tab) where the user is logged in
+type SafeUsersClients struct {
+	sync.RWMutex
+	items map[int]map[*Client]bool
+}
+
+func NewSafeUsersClients() *SafeUsersClients {
+	uc := &SafeUsersClients{}
+	uc.items = make(map[int]map[*Client]bool)
+	return uc
+}
+
+// Add adds the client to its user's clients, returns true if it is the first client of the user
+func (uc *SafeUsersClients) Add(client *Client) bool {
+	uc.Lock()
+	defer uc.Unlock()
+	clients, ok := uc.items[client.User.ID]
+	if !ok {
+		clients = make(map[*Client]bool)
+		uc.items[client.User.ID] = clients
+	}
+	clients[client] = true
+	return len(clients) == 1
+}
+
+// Remove removes the client from its user's clients, returns true if it was the last client of the user
+func (uc *SafeUsersClients) Remove(client *Client) bool {
+	uc.Lock()
+	defer uc.Unlock()
+	clients, ok := uc.items[client.User.ID]
+	if !ok || !clients[client] {
+		return false
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(uc.items, client.User.ID)
+		return true
+	}
+	return false
+}
+
+func (uc *SafeUsersClients) Get(userID int) []*Client {
+	uc.RLock()
+	defer uc.RUnlock()
+	clients := make([]*Client, 0, len(uc.items[userID]))
+	for client := range uc.items[userID] {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+func (uc *SafeUsersClients) Count(userID int) int {
+	uc.RLock()
+	defer uc.RUnlock()
+	return len(uc.items[userID])
+}
+
 // Hub maintains the set of active clients and broadcasts messages to the clients.
 type Hub struct {
 	// Registered Clients.
 	Clients *SafeClientsMap
 
+	// Registered clients of logged in users, indexed by the users' ids
+	UsersClients *SafeUsersClients
+
 	// Inbound messages from a client.
 	messageForAll chan []byte
 
@@ -63,6 +124,7 @@ func NewHub() *Hub {
 	return &Hub{
 		messageForAll: make(chan []byte),
 		Clients:       NewSafeMap(),
+		UsersClients:  NewSafeUsersClients(),
 		register:      make(chan *Client),
 		unregister:    make(chan *Client),
 		// OnlineUsersRequest: make(chan *Client),
@@ -74,6 +136,9 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.Clients.Set(client, true)
+			if client.User != nil {
+				h.UsersClients.Add(client)
+			}
 			client.ClientRegistered <- struct{}{}
 		case client := <-h.unregister:
 			if _, ok := h.Clients.Get(client); ok {
@@ -86,9 +151,12 @@ func (h *Hub) Run() {
 				case client.ReceivedMessages <- message:
 				default:
 					// If the client's send buffer is full, then the hub assumes that the client is dead or stuck.
-					// In this case, the hub unregisters the client.
-					close(client.ReceivedMessages)
+					// In this case, the hub unregisters the client. The channel is closed by the client's ReadPump only,
+					// which can still send replies to it
 					delete(h.Clients.items, client)
+					if client.User != nil {
+						h.UsersClients.Remove(client)
+					}
 				}
 			}
 			h.Clients.Unlock()
@@ -98,7 +166,8 @@ func (h *Hub) Run() {
 	}
 }
 
-type MapID map[int]*Client
+// MapID contains the clients of online users indexed by the users' ids
+type MapID map[int][]*Client
 
 func (m MapID) CheckID(userID int) bool {
 	_, ok := m[userID]
@@ -110,32 +179,37 @@ func (h *Hub) RegisterToHub(c *Client) {
 	h.register <- c
 }
 
-// UnRegisterToHub removes the client from its hub
-func (h *Hub) UnRegisterFromHub(c *Client) {
+// UnRegisterToHub removes the client from its hub.
+// Returns true if the client was the last client of a logged in user, so the user is offline now
+func (h *Hub) UnRegisterFromHub(c *Client) bool {
+	// the user's clients are updated before returning, so the online status of the user is known right away
+	isLast := false
+	if c.User != nil {
+		isLast = h.UsersClients.Remove(c)
+	}
 	h.unregister <- c
+	return isLast
 }
 
 func (h *Hub) GetOnlineUsers() MapID {
 	usersID := make(MapID)
-	h.Clients.RLock()
-	defer h.Clients.RUnlock()
-	for client := range h.Clients.items {
-		if client.User != nil {
-			usersID[client.User.ID] = client
+	h.UsersClients.RLock()
+	defer h.UsersClients.RUnlock()
+	for userID, clients := range h.UsersClients.items {
+		for client := range clients {
+			usersID[userID] = append(usersID[userID], client)
 		}
 	}
 	return usersID
 }
 
-func (h *Hub) GetUsersClient(userID int) (*Client, bool) {
-	h.Clients.RLock()
-	defer h.Clients.RUnlock()
-	for client := range h.Clients.items {
-		if client.User != nil && client.User.ID == userID {
-			return client, true
-		}
-	}
-	return nil, false
+// GetUsersClients returns the clients of all the devices of the user, the slice is empty if the user is offline
+func (h *Hub) GetUsersClients(userID int) []*Client {
+	return h.UsersClients.Get(userID)
+}
+
+func (h *Hub) IsUserOnline(userID int) bool {
+	return h.UsersClients.Count(userID) != 0
 }
 
 func (h *Hub) IsThereClient(client *Client) bool {
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"sync"
+
+	"forum/model"
+)
+
+// OpenedChat is a chat which is shown in one of the chat windows of a client
+type OpenedChat struct {
+	ChatID   int
+	ChatName string
+	Type     int
+	// User is the recipient of a private chat, nil for a group chat
+	User *model.User
+}
+
+// SafeOpenedChats is the set of the chats opened by a client, indexed by chat ids
+type SafeOpenedChats struct {
+	sync.RWMutex
+	items map[int]OpenedChat
+}
+
+func NewSafeOpenedChats() *SafeOpenedChats {
+	oc := &SafeOpenedChats{}
+	oc.items = make(map[int]OpenedChat)
+	return oc
+}
+
+func (oc *SafeOpenedChats) Open(chat OpenedChat) {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.items[chat.ChatID] = chat
+}
+
+func (oc *SafeOpenedChats) Get(chatID int) (OpenedChat, bool) {
+	oc.RLock()
+	defer oc.RUnlock()
+	chat, ok := oc.items[chatID]
+	return chat, ok
+}
+
+func (oc *SafeOpenedChats) IsOpened(chatID int) bool {
+	_, ok := oc.Get(chatID)
+	return ok
+}
+
+// Close removes the chat from the set, returns false if the chat was not opened
+func (oc *SafeOpenedChats) Close(chatID int) bool {
+	oc.Lock()
+	defer oc.Unlock()
+	_, ok := oc.items[chatID]
+	delete(oc.items, chatID)
+	return ok
+}
+
+func (oc *SafeOpenedChats) CloseAll() {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.items = make(map[int]OpenedChat)
+}
+
+func (oc *SafeOpenedChats) Len() int {
+	oc.RLock()
+	defer oc.RUnlock()
+	return len(oc.items)
+}
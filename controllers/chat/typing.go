@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+type typingState struct {
+	lastRelayed time.Time
+	expires     time.Time
+	timer       *time.Timer
+}
+
+// SafeTypingTimers keeps the typing state of a client for each chat where the client's user is typing.
+// The typing stops automatically, if the client doesn't confirm it during a timeout
+type SafeTypingTimers struct {
+	sync.Mutex
+	items map[int]*typingState
+}
+
+func NewSafeTypingTimers() *SafeTypingTimers {
+	tt := &SafeTypingTimers{}
+	tt.items = make(map[int]*typingState)
+	return tt
+}
+
+/*
+Start starts or prolongs the typing in the chat. After 'timeout' without prolongation the typing is stopped and 'onExpire' is called.
+Returns true if the typing has to be relayed to the other members of the chat:
+when the typing is started or it was relayed more than 'throttle' ago
+*/
+func (tt *SafeTypingTimers) Start(chatID int, throttle, timeout time.Duration, onExpire func()) bool {
+	tt.Lock()
+	defer tt.Unlock()
+
+	now := time.Now()
+	state, ok := tt.items[chatID]
+	if ok {
+		state.expires = now.Add(timeout)
+		state.timer.Reset(timeout)
+		if now.Sub(state.lastRelayed) < throttle {
+			return false
+		}
+		state.lastRelayed = now
+		return true
+	}
+
+	state = &typingState{lastRelayed: now, expires: now.Add(timeout)}
+	state.timer = time.AfterFunc(timeout, func() {
+		if tt.expire(chatID, state) {
+			onExpire()
+		}
+	})
+	tt.items[chatID] = state
+	return true
+}
+
+// expire removes the state if it was not prolonged, returns true if the state is removed
+func (tt *SafeTypingTimers) expire(chatID int, state *typingState) bool {
+	tt.Lock()
+	defer tt.Unlock()
+	if tt.items[chatID] != state || time.Now().Before(state.expires) {
+		return false
+	}
+	delete(tt.items, chatID)
+	return true
+}
+
+// Stop stops the typing in the chat, returns true if the user was typing
+func (tt *SafeTypingTimers) Stop(chatID int) bool {
+	tt.Lock()
+	defer tt.Unlock()
+	state, ok := tt.items[chatID]
+	if !ok {
+		return false
+	}
+	state.timer.Stop()
+	delete(tt.items, chatID)
+	return true
+}
+
+// StopAll stops the typing in all the chats, returns the ids of the chats where the user was typing
+func (tt *SafeTypingTimers) StopAll() []int {
+	tt.Lock()
+	defer tt.Unlock()
+	chatsID := make([]int, 0, len(tt.items))
+	for chatID, state := range tt.items {
+		state.timer.Stop()
+		chatsID = append(chatsID, chatID)
+	}
+	tt.items = make(map[int]*typingState)
+	return chatsID
+}
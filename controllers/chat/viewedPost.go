@@ -0,0 +1,33 @@
+package chat
+
+import "sync"
+
+// SafeViewedPost keeps the id of the post opened by a client.
+// The client is subscribed to the new comments of this post, 0 means no post is opened
+type SafeViewedPost struct {
+	sync.RWMutex
+	postID int
+}
+
+func (vp *SafeViewedPost) View(postID int) {
+	vp.Lock()
+	defer vp.Unlock()
+	vp.postID = postID
+}
+
+// Leave unsubscribes the client from the post, returns false if the post was not opened
+func (vp *SafeViewedPost) Leave(postID int) bool {
+	vp.Lock()
+	defer vp.Unlock()
+	if vp.postID != postID {
+		return false
+	}
+	vp.postID = 0
+	return true
+}
+
+func (vp *SafeViewedPost) IsViewing(postID int) bool {
+	vp.RLock()
+	defer vp.RUnlock()
+	return postID != 0 && vp.postID == postID
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+changes the content of the current user's chat message and sends the change to all the members of the chat
+*/
+func replyEditChatMessage(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	editedMessage, err := parse.PayloadToEditedChatMessage(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for editing a chat message: '%s'", message.Payload), err)
+	}
+
+	errmessage := editedMessage.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	editedMessage.ChatID, err = getOwnChatMessageChat(app, currConnection, editedMessage.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	editedMessage.DateEdit = time.Now()
+
+	err = app.ForumData.EditChatMessage(editedMessage.ID, editedMessage.MessageContent, editedMessage.DateEdit)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat message %d is deleted", editedMessage.ID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "editing the chat message in DB failed", err)
+	}
+	app.InfoLog.Printf("chat message %d is edited by %s", editedMessage.ID, currConnection.session.User)
+
+	err = sendToChatMembers(app, currConnection, editedMessage.ChatID, wsmodel.ChatMessageEdited, editedMessage)
+	if err != nil {
+		return nil, err
+	}
+	return editedMessage, nil
+}
+
+/*
+replaces the current user's chat message with a tombstone and sends the change to all the members of the chat
+*/
+func replyDeleteChatMessage(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	deletedMessage, err := parse.PayloadToDeletedChatMessage(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for deleting a chat message: '%s'", message.Payload), err)
+	}
+
+	errmessage := deletedMessage.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	deletedMessage.ChatID, err = getOwnChatMessageChat(app, currConnection, deletedMessage.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	deletedMessage.DateDelete = time.Now()
+
+	err = app.ForumData.MarkChatMessageDeleted(deletedMessage.ID, deletedMessage.DateDelete)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the chat message %d is deleted already", deletedMessage.ID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "deleting the chat message in DB failed", err)
+	}
+	app.InfoLog.Printf("chat message %d is deleted by %s", deletedMessage.ID, currConnection.session.User)
+
+	err = sendToChatMembers(app, currConnection, deletedMessage.ChatID, wsmodel.ChatMessageDeleted, deletedMessage)
+	if err != nil {
+		return nil, err
+	}
+	return deletedMessage, nil
+}
+
+/*
+returns the chat id of the chat message, if the current user is the author of the message
+*/
+func getOwnChatMessageChat(app *application.Application, currConnection *usersConnection, messageID int, message wsmodel.WSMessage) (int, error) {
+	chatID, authorID, err := app.ForumData.GetChatMessageAuthor(messageID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return 0, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a chat message with id '%d'", messageID))
+	}
+	if err != nil {
+		return 0, errHelper(app, currConnection, "get the chat message from DB failed", err)
+	}
+
+	if authorID != currConnection.session.User.ID {
+		return 0, badRequestHelper(app, currConnection, message, "only the author can change the message")
+	}
+	return chatID, nil
+}
+
+/*
+sends the message to all the devices of the chat members, except the current client.
+Offline members will get the changes with the chat history
+*/
+func sendToChatMembers(app *application.Application, currConnection *usersConnection, chatID int, messageType string, data any) error {
+	members, err := app.ForumData.GetChatMembers(chatID)
+	if err != nil {
+		return errHelper(app, currConnection, "get the chat members from DB failed", err)
+	}
+
+	err = sendToGroupChatMembers(app, currConnection, members, messageType, data)
+	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+type receiptSetter func(messageID, userID int, date time.Time) (bool, error)
+
+// processMessageDelivered saves that a chat message was delivered to the current user and notifies the message's author
+func processMessageDelivered(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	_, err := processChatMessageReceipt(app, currConnection, message, app.ForumData.SetChatMessageDelivered)
+	return err
+}
+
+// processMessageRead saves that a chat message was read by the current user and notifies the message's author
+func processMessageRead(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	receipt, err := processChatMessageReceipt(app, currConnection, message, app.ForumData.SetChatMessageRead)
+	if err != nil {
+		return err
+	}
+
+	err = app.ForumData.SetLastReadChatMessage(receipt.ChatID, receipt.UserID, receipt.MessageID)
+	if err != nil {
+		return errHelper(app, currConnection, "marking the chat message as read failed", err)
+	}
+	return nil
+}
+
+/*
+saves the receipt for a chat message using the 'setReceipt' function.
+If the receipt is new, sends it to the author of the message (if the author is online).
+Acknowledgements don't have replies, so wrong acknowledgements are only logged.
+*/
+func processChatMessageReceipt(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage, setReceipt receiptSetter) (wsmodel.ChatMessageReceipt, error) {
+	receipt, err := parse.PayloadToChatMessageReceipt(message.Payload)
+	if err != nil {
+		return receipt, ignoreMessage(app, message, fmt.Sprintf("invalid payload: %v", err))
+	}
+
+	errmessage := receipt.Validate()
+	if errmessage != "" {
+		return receipt, ignoreMessage(app, message, errmessage)
+	}
+
+	chatID, authorID, err := app.ForumData.GetChatMessageAuthor(receipt.MessageID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return receipt, ignoreMessage(app, message, fmt.Sprintf("there is no chat message with id %d", receipt.MessageID))
+	}
+	if err != nil {
+		return receipt, errHelper(app, currConnection, "get the chat message from DB failed", err)
+	}
+
+	userID := currConnection.session.User.ID
+	if authorID == userID {
+		return receipt, ignoreMessage(app, message, "the author can't acknowledge their own message")
+	}
+	isMember, err := app.ForumData.IsChatMember(chatID, userID)
+	if err != nil {
+		return receipt, errHelper(app, currConnection, "get the chat members from DB failed", err)
+	}
+	if !isMember {
+		return receipt, ignoreMessage(app, message, fmt.Sprintf("the user %s is not a member of the chat %d", currConnection.session.User, chatID))
+	}
+
+	receipt.ChatID = chatID
+	receipt.UserID = userID
+	receipt.Date = time.Now()
+
+	isNew, err := setReceipt(receipt.MessageID, userID, receipt.Date)
+	if err != nil {
+		return receipt, errHelper(app, currConnection, "saving the chat message receipt to DB failed", err)
+	}
+	if !isNew {
+		return receipt, nil
+	}
+
+	return receipt, sendMessageToUser(app, currConnection, authorID, message.Type, receipt)
+}
+
+/*
+saves that the chat message was delivered to the recipient, it is called when the server sends the message
+to the recipient's devices. If the receipt is new, it is sent to all the devices of the author
+*/
+func setChatMessageDelivered(app *application.Application, currConnection *usersConnection, chatMessage wsmodel.ChatMessage, recipientID int) error {
+	receipt := wsmodel.ChatMessageReceipt{MessageID: chatMessage.ID, ChatID: chatMessage.ChatID, UserID: recipientID, Date: time.Now()}
+	isNew, err := app.ForumData.SetChatMessageDelivered(receipt.MessageID, recipientID, receipt.Date)
+	if err != nil {
+		return errHelper(app, currConnection, "saving the chat message receipt to DB failed", err)
+	}
+	if !isNew {
+		return nil
+	}
+
+	if chatMessage.Author.ID == currConnection.session.User.ID {
+		err = sendSuccessMessage(app, currConnection, wsmodel.MessageDelivered, receipt)
+		if err != nil {
+			return err
+		}
+	}
+	return sendMessageToUser(app, currConnection, chatMessage.Author.ID, wsmodel.MessageDelivered, receipt)
+}
+
+/*
+logs the reason why the message is ignored and returns a warning
+*/
+func ignoreMessage(app *application.Application, message wsmodel.WSMessage, reason string) error {
+	app.InfoLog.Printf("websocket:: message '%s' is ignored: %s", message.Type, reason)
+	return errors.Join(wsmodel.ErrWarning, errors.New(reason))
+}
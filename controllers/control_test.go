@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
+	"forum/controllers/liker"
 	"forum/model"
 	"forum/wsmodel"
 )
@@ -35,3 +38,99 @@ func TestCreateUser(t *testing.T) {
 		t.Fatalf("result is\n%s\n, but expected to be\n%s\n", user, &userExpected)
 	}
 }
+
+func TestDescribeAgent(t *testing.T) {
+	tests := []struct {
+		agent string
+		want  string
+	}{
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0", "Firefox on Linux"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/117.0.0.0 Safari/537.36 Edg/117.0.2045.60", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", "Safari on iPhone"},
+		{"Mozilla/5.0 (Linux; Android 13) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/117.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"curl/8.0", "Unknown device"},
+		{"", "Unknown device"},
+	}
+	for i, test := range tests {
+		got := describeAgent(test.agent)
+		if got != test.want {
+			t.Errorf("# %d: got '%s', want '%s'", i, got, test.want)
+		}
+	}
+}
+
+func TestLoginRetryTime(t *testing.T) {
+	throttle := loginThrottle{window: time.Hour, backoffAfter: 3, lockoutAfter: 6, baseDelay: time.Second, lockout: time.Minute}
+	last := time.Now()
+	failures := func(n int) []time.Time {
+		times := make([]time.Time, n)
+		for i := range times {
+			times[i] = last.Add(-time.Duration(i) * time.Second)
+		}
+		return times
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Time
+		lockout  bool
+	}{
+		{0, time.Time{}, false},
+		{2, time.Time{}, false},
+		{3, last.Add(time.Second), false},
+		{4, last.Add(2 * time.Second), false},
+		{5, last.Add(4 * time.Second), false},
+		{6, last.Add(time.Minute), true},
+		{20, last.Add(time.Minute), true},
+	}
+	for _, test := range tests {
+		got := throttle.retryTime(failures(test.failures))
+		if !got.Equal(test.want) {
+			t.Errorf("%d failures: got the retry time %s, want %s", test.failures, got, test.want)
+		}
+		if throttle.isLockout(failures(test.failures)) != test.lockout {
+			t.Errorf("%d failures: got lockout %t, want %t", test.failures, !test.lockout, test.lockout)
+		}
+	}
+}
+
+func TestCreateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := createRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RECOVERY_CODES_NUMBER || len(hashes) != RECOVERY_CODES_NUMBER {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), RECOVERY_CODES_NUMBER)
+	}
+
+	unique := map[string]bool{}
+	for i, code := range codes {
+		unique[code] = true
+		typed := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+		if hashSecretToken(normalizeRecoveryCode(typed)) != hashes[i] {
+			t.Errorf("the code '%s' typed as '%s' doesn't match its hash", code, typed)
+		}
+	}
+	if len(unique) != RECOVERY_CODES_NUMBER {
+		t.Errorf("got %d unique codes, want %d", len(unique), RECOVERY_CODES_NUMBER)
+	}
+}
+
+func TestLikesCounters(t *testing.T) {
+	likes := liker.LikesNumbers{
+		MessageType:      model.POST,
+		MessageID:        3,
+		Likes:            2,
+		Dislikes:         1,
+		UserWithReaction: &model.User{ID: 5, Name: "user"},
+		UserReaction:     1,
+	}
+	data, err := json.Marshal(likes.Counters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"messageType":"post","messageID":3,"likes":2,"dislikes":1}`
+	if string(data) != want {
+		t.Errorf("got the broadcast %s, want %s", data, want)
+	}
+}
@@ -3,9 +3,12 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"forum/application"
 	"forum/controllers/chat"
+	"forum/model"
 	"forum/session"
 	"forum/wsmodel"
 )
@@ -22,15 +25,28 @@ const (
 	F_CATEGORIESID = "categoriesID"
 	F_LIKEBY       = "likedby"
 	F_DISLIKEBY    = "dislikedby"
+	F_TOKEN        = "token"
 )
 
 const POST_PREVIEW_LENGTH = 450
 
 const (
-	POSTS_ON_POSTSVIEW    = 10
-	CHAT_MESSAGES_PORTION = 10
+	POSTS_ON_POSTSVIEW     = 10
+	CHAT_MESSAGES_PORTION  = 10
+	CHAT_BACKLOG_LIMIT     = 100 // maximum unread chat messages sent to a user when the user comes online
+	SEARCH_RESULTS_PORTION = 10
+	MODERATION_LOG_PORTION = 20
+	REPORTS_PORTION        = 20
+	PROFILE_ACTIVITY_LIMIT = 10 // the number of the latest posts and comments shown in a profile
 )
 
+const (
+	TYPING_THROTTLE = 3 * time.Second // minimum interval between relaying the typing of a user to the chat
+	TYPING_TIMEOUT  = 6 * time.Second // the typing is stopped if a client doesn't confirm it during the timeout
+)
+
+const LAST_SEEN_PERIOD = session.LAST_SEEN_PERIOD // the last seen time of a session is updated in DB not more often than once in the period
+
 const USER_IMAGES_DIR = "./images"
 
 const (
@@ -41,19 +57,51 @@ const (
 type usersConnection struct {
 	session *session.Session
 	Client  *chat.Client
+	device  session.Device // the browser of the connection, kept in the sessions created on the connection
+	// the time the last seen time of the session was updated in DB
+	lastSeenUpdate time.Time
+	// guards the session and the client, which are changed by ReadPump and by SessionCheckPump
+	mu sync.Mutex
+	// closed by ReadPump, when the connection is closed
+	closed chan struct{}
+	// the login, which waits for the second factor
+	secondFactor *pendingSecondFactor
+}
+
+// isClosed returns true if ReadPump has closed the connection
+func (uc *usersConnection) isClosed() bool {
+	select {
+	case <-uc.closed:
+		return true
+	default:
+		return false
+	}
 }
 
 func (uc *usersConnection) renewClientForUser(app *application.Application, session *session.Session) error {
+	err := stopAllTyping(app, uc)
+	if err != nil {
+		return err
+	}
+
 	oldClient := uc.Client
 	// (online changes)uc.Client = chat.NewClient(app.Hub, session.User, uc.Client.Conn, uc.Client.ReceivedMessages, uc.Client.ClientRegistered)
-	err := uc.createNewClientAndSendUserOnline(app, uc.Client.Conn, uc.Client.ReceivedMessages, uc.Client.ClientRegistered)
+	err = uc.createNewClientAndSendUserOnline(app, uc.Client.Conn, uc.Client.ReceivedMessages, uc.Client.ClientRegistered)
 	if err != nil {
 		return err
 	}
 	app.InfoLog.Printf("registered new client: %s", uc.Client)
-	
+
+	if session.IsLoggedin() {
+		// deliver the chat messages which were sent while the user was offline
+		err = sendUnreadChatMessages(app, uc)
+		if err != nil {
+			return err
+		}
+	}
+
 	// (online changes)app.Hub.UnRegisterFromHub(oldClient)
-	err=uc.deleteClientAndSendUserOffline(app,oldClient)
+	err = uc.deleteClientAndSendUserOffline(app, oldClient)
 	if err != nil {
 		return err
 	}
@@ -68,19 +116,64 @@ type (
 
 var (
 	replierAuthenticators = map[string]replierAuthenticator{
-		wsmodel.RegisterRequest: replyRegister,
-		wsmodel.LoginRequest:    replyLogin,
-		wsmodel.LogoutRequest:   replyLogout,
+		wsmodel.RegisterRequest:          replyRegister,
+		wsmodel.LoginRequest:             replyLogin,
+		wsmodel.LogoutRequest:            replyLogout,
+		wsmodel.LogoutEverywhereRequest:  replyLogoutEverywhere,
+		wsmodel.LoginSecondFactorRequest: replyLoginSecondFactor,
 	}
 	repliers = map[string]replier{
 		wsmodel.PostsPortionRequest:           sendReplyForLoggedUser(replyPosts),
 		wsmodel.FullPostAndCommentsRequest:    sendReplyForLoggedUser(replyFullPostAndComments),
-		wsmodel.NewPostRequest:                sendReplyForLoggedUser(replyNewPost),
-		wsmodel.NewCommentRequest:             sendReplyForLoggedUser(replyNewComment),
+		wsmodel.NewPostRequest:                sendReplyForContentAuthor(replyNewPost),
+		wsmodel.NewCommentRequest:             sendReplyForContentAuthor(replyNewComment),
 		wsmodel.OpenChatRequest:               sendReplyForLoggedUser(replyOpenChat),
-		wsmodel.SendMessageToOpendChatRequest: sendReplyForLoggedUser(replySendMessageToOpendChat),
+		wsmodel.SendMessageToOpendChatRequest: sendReplyForContentAuthor(replySendMessageToOpendChat),
 		wsmodel.CloseChatRequest:              sendReplyForLoggedUser(replyCloseChat),
 		wsmodel.ChatPortionRequest:            sendReplyForLoggedUser(replyChatPortion),
+		wsmodel.CreateGroupChatRequest:        sendReplyForContentAuthor(replyCreateGroupChat),
+		wsmodel.GroupChatsRequest:             sendReplyForLoggedUser(replyGroupChats),
+		wsmodel.OpenGroupChatRequest:          sendReplyForLoggedUser(replyOpenGroupChat),
+		wsmodel.GroupChatPortionRequest:       sendReplyForLoggedUser(replyGroupChatPortion),
+		wsmodel.SendMessageToGroupChatRequest: sendReplyForContentAuthor(replySendMessageToGroupChat),
+		wsmodel.AddGroupChatMemberRequest:     sendReplyForLoggedUser(replyAddGroupChatMember),
+		wsmodel.RemoveGroupChatMemberRequest:  sendReplyForLoggedUser(replyRemoveGroupChatMember),
+		wsmodel.LeaveGroupChatRequest:         sendReplyForLoggedUser(replyLeaveGroupChat),
+		wsmodel.EditChatMessageRequest:        sendReplyForContentAuthor(replyEditChatMessage),
+		wsmodel.DeleteChatMessageRequest:      sendReplyForLoggedUser(replyDeleteChatMessage),
+		wsmodel.SearchRequest:                 sendReplyForLoggedUser(replySearch),
+		wsmodel.ReactionRequest:               sendReplyForLoggedUser(replyReaction),
+		wsmodel.EditPostRequest:               sendReplyForContentAuthor(replyEditPost),
+		wsmodel.DeletePostRequest:             sendReplyForLoggedUser(replyDeletePost),
+		wsmodel.EditCommentRequest:            sendReplyForContentAuthor(replyEditComment),
+		wsmodel.DeleteCommentRequest:          sendReplyForLoggedUser(replyDeleteComment),
+		wsmodel.LockPostRequest:               sendReplyForRole(model.ROLE_MODERATOR, replyLockPost),
+		wsmodel.BanUserRequest:                sendReplyForRole(model.ROLE_MODERATOR, replyBanUser),
+		wsmodel.SuspendUserRequest:            sendReplyForRole(model.ROLE_MODERATOR, replySuspendUser),
+		wsmodel.MuteUserRequest:               sendReplyForRole(model.ROLE_MODERATOR, replyMuteUser),
+		wsmodel.SetUserRoleRequest:            sendReplyForRole(model.ROLE_ADMIN, replySetUserRole),
+		wsmodel.ModerationLogRequest:          sendReplyForRole(model.ROLE_MODERATOR, replyModerationLog),
+		wsmodel.BlockUserRequest:              sendReplyForLoggedUser(replyBlockUser),
+		wsmodel.UnblockUserRequest:            sendReplyForLoggedUser(replyUnblockUser),
+		wsmodel.BlockedUsersRequest:           sendReplyForLoggedUser(replyBlockedUsers),
+		wsmodel.GetProfileRequest:             sendReplyForLoggedUser(replyGetProfile),
+		wsmodel.UpdateProfileRequest:          sendReplyForLoggedUser(replyUpdateProfile),
+		wsmodel.SendVerificationEmailRequest:  sendReplyForLoggedUser(replySendVerificationEmail),
+		wsmodel.ReportRequest:                 sendReplyForLoggedUser(replyReport),
+		wsmodel.ListSessionsRequest:           sendReplyForLoggedUser(replyListSessions),
+		wsmodel.RevokeSessionRequest:          sendReplyForLoggedUser(replyRevokeSession),
+		wsmodel.SetupTwoFactorRequest:         sendReplyForLoggedUser(replySetupTwoFactor),
+		wsmodel.EnableTwoFactorRequest:        sendReplyForLoggedUser(replyEnableTwoFactor),
+		wsmodel.DisableTwoFactorRequest:       sendReplyForLoggedUser(replyDisableTwoFactor),
+		wsmodel.ReportsRequest:                sendReplyForRole(model.ROLE_MODERATOR, replyReports),
+		wsmodel.ClaimReportRequest:            sendReplyForRole(model.ROLE_MODERATOR, replyClaimReport),
+		wsmodel.ResolveReportRequest:          sendReplyForRole(model.ROLE_MODERATOR, replyResolveReport),
+		wsmodel.DismissReportRequest:          sendReplyForRole(model.ROLE_MODERATOR, replyDismissReport),
+		wsmodel.MessageDelivered:              processForLoggedUser(processMessageDelivered),
+		wsmodel.MessageRead:                   processForLoggedUser(processMessageRead),
+		wsmodel.TypingStarted:                 processForLoggedUser(processTypingStarted),
+		wsmodel.TypingStopped:                 processForLoggedUser(processTypingStopped),
+		wsmodel.ClosePost:                     processForLoggedUser(processClosePost),
 	}
 )
 
@@ -94,6 +187,10 @@ func sendReplyForLoggedUser(createReplyData replyDataCreator) replier {
 		if err != nil {
 			return err
 		}
+		err = checkNotSuspended(app, currConnection)
+		if err != nil {
+			return err
+		}
 
 		replyData, err := createReplyData(app, currConnection, message)
 		if err != nil {
@@ -103,3 +200,52 @@ func sendReplyForLoggedUser(createReplyData replyDataCreator) replier {
 		return sendReply(app, currConnection, message, replyData)
 	}
 }
+
+/*
+creates a replier for requests which are allowed to the users with the role 'role' or higher only
+*/
+func sendReplyForRole(role model.Role, createReplyData replyDataCreator) replier {
+	return sendReplyForLoggedUser(func(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+		err := checkRole(app, currConnection, message, role)
+		if err != nil {
+			return nil, err
+		}
+		return createReplyData(app, currConnection, message)
+	})
+}
+
+/*
+creates a replier for requests which add or change content. The author has to be logged in,
+has to have the email verified and must not be muted
+*/
+func sendReplyForContentAuthor(createReplyData replyDataCreator) replier {
+	return sendReplyForLoggedUser(func(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+		err := checkVerified(app, currConnection, message)
+		if err != nil {
+			return nil, err
+		}
+		err = checkNotMuted(app, currConnection, message)
+		if err != nil {
+			return nil, err
+		}
+		return createReplyData(app, currConnection, message)
+	})
+}
+
+/*
+creates a replier for messages which don't need a reply, e.g. acknowledgements
+*/
+func processForLoggedUser(process replier) replier {
+	return func(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+		err := checkLoggedStatus(app, currConnection, message)
+		if err != nil {
+			return err
+		}
+		err = checkNotSuspended(app, currConnection)
+		if err != nil {
+			return err
+		}
+
+		return process(app, currConnection, message)
+	}
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"forum/application"
+	"forum/errorhandle"
+	"forum/model"
+	"forum/wsmodel"
+)
+
+const (
+	VERIFY_TOKEN_TTL       = 24 * time.Hour // an email verification token can be used during the time only
+	VERIFY_REQUESTS_LIMIT  = 3              // maximum number of verification emails sent to a user during VERIFY_REQUESTS_PERIOD
+	VERIFY_REQUESTS_PERIOD = time.Hour
+)
+
+var errTooManyEmails = errors.New("too many emails are sent to the user")
+
+/*
+VerifyEmail makes the owner of the token given in the query parameter F_TOKEN verified and redirects to the main page.
+The user's online clients are notified
+*/
+func VerifyEmail(app *application.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get(F_TOKEN)
+		if token == "" {
+			errorhandle.ClientError(app, w, r, http.StatusBadRequest, "email verification: token missing")
+			return
+		}
+
+		userID, err := app.ForumData.VerifyEmail(hashSecretToken(token), time.Now())
+		if errors.Is(err, model.ErrNoRecord) {
+			errorhandle.ClientError(app, w, r, http.StatusBadRequest, "email verification: the token is invalid or expired")
+			return
+		}
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "verifying the email in DB failed", err)
+			return
+		}
+		app.InfoLog.Printf("the email of the user %d is verified", userID)
+
+		sendEmailVerified(app, userID)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}
+}
+
+/*
+sends a new verification email to the current user, if the email is not verified yet
+*/
+func replySendVerificationEmail(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	user, err := getExistingUser(app, currConnection, currConnection.session.User.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	if user.Verified {
+		return nil, badRequestHelper(app, currConnection, message, "the email is verified already")
+	}
+
+	err = sendVerificationEmail(app, user)
+	if errors.Is(err, errTooManyEmails) {
+		return nil, badRequestHelper(app, currConnection, message, "too many verification emails are sent, try again later")
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "sending the verification email failed", err)
+	}
+	return user.Email, nil
+}
+
+/*
+creates an email verification token for the user and sends it to the user's email.
+Returns errTooManyEmails if VERIFY_REQUESTS_LIMIT is reached
+*/
+func sendVerificationEmail(app *application.Application, user *model.User) error {
+	now := time.Now()
+	count, err := app.ForumData.CountEmailVerifications(user.ID, now.Add(-VERIFY_REQUESTS_PERIOD))
+	if err != nil {
+		return fmt.Errorf("counting the email verifications in DB failed: %w", err)
+	}
+	if count >= VERIFY_REQUESTS_LIMIT {
+		return errTooManyEmails
+	}
+
+	token, err := createSecretToken()
+	if err != nil {
+		return fmt.Errorf("creating the email verification token failed: %w", err)
+	}
+	err = app.ForumData.InsertEmailVerification(user.ID, user.Email, hashSecretToken(token), now, now.Add(VERIFY_TOKEN_TTL))
+	if err != nil {
+		return fmt.Errorf("adding the email verification to DB failed: %w", err)
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nto confirm the email of your forum account follow the link:\n%s/verify?%s=%s\n\n"+
+		"The link expires in %d hours. Until the email is confirmed, you can read the forum but cannot post or chat.",
+		user.Name, app.BaseURL, F_TOKEN, token, int(VERIFY_TOKEN_TTL.Hours()))
+	err = app.Mailer.Send(user.Email, "Email verification", body)
+	if err != nil {
+		return err
+	}
+
+	app.InfoLog.Printf("verification email is sent to the user %s", user)
+	return nil
+}
+
+/*
+returns nil if the current user has verified the email. Otherwise sends an error message and returns an error
+*/
+func checkVerified(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	verified, err := isVerified(app, currConnection)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return badRequestHelper(app, currConnection, message, "the email is not verified")
+	}
+	return nil
+}
+
+func isVerified(app *application.Application, currConnection *usersConnection) (bool, error) {
+	verified, err := app.ForumData.IsUserVerified(currConnection.session.User.ID)
+	if err != nil {
+		return false, errHelper(app, currConnection, "get the verification status of the user from DB failed", err)
+	}
+	return verified, nil
+}
+
+/*
+sends the message EmailVerified to all the online clients of the user
+*/
+func sendEmailVerified(app *application.Application, userID int) {
+	message, err := wsmodel.CreateMessage(wsmodel.EmailVerified, "success", userID)
+	if err != nil {
+		app.ErrLog.Printf("creating the message %s failed: %v", wsmodel.EmailVerified, err)
+		return
+	}
+	wsMessage, err := json.Marshal(message)
+	if err != nil {
+		app.ErrLog.Printf("marshaling the message %s failed: %v", wsmodel.EmailVerified, err)
+		return
+	}
+
+	for _, client := range app.Hub.GetUsersClients(userID) {
+		client.WriteMessage(wsMessage)
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+sends the new post to the other clients of logged in users, so their feeds are updated live.
+The post is sent as a preview, like in the portions of posts. The users who blocked the author don't get the post
+*/
+func sendPostCreated(app *application.Application, currConnection *usersConnection, postID int) error {
+	post, err := app.ForumData.GetPostByID(postID, 0)
+	if err != nil {
+		return errHelper(app, currConnection, fmt.Sprintf("get the new post %d from DB failed", postID), err)
+	}
+	createPostsPreview([]*model.Post{post})
+
+	isNotBlocker, err := getIsNotBlocker(app, currConnection, currConnection.session.User.ID)
+	if err != nil {
+		return err
+	}
+	return sendMessageToLoggedClients(app, currConnection, wsmodel.PostCreated, post, isNotBlocker)
+}
+
+/*
+sends the new comment to the other clients which are viewing the post of the comment,
+except the clients of the users who blocked the author
+*/
+func sendCommentCreated(app *application.Application, currConnection *usersConnection, commentID int) error {
+	comment, err := app.ForumData.GetCommentByID(commentID)
+	if err != nil {
+		return errHelper(app, currConnection, fmt.Sprintf("get the new comment %d from DB failed", commentID), err)
+	}
+
+	isNotBlocker, err := getIsNotBlocker(app, currConnection, currConnection.session.User.ID)
+	if err != nil {
+		return err
+	}
+	isRecipient := func(client *chat.Client) bool {
+		return client.ViewedPost.IsViewing(comment.PostID) && isNotBlocker(client)
+	}
+	return sendMessageToLoggedClients(app, currConnection, wsmodel.CommentCreated, comment, isRecipient)
+}
+
+/*
+returns a function which is false for the clients of the users who blocked the user with the given id
+*/
+func getIsNotBlocker(app *application.Application, currConnection *usersConnection, userID int) (func(*chat.Client) bool, error) {
+	blockersID, err := app.ForumData.GetBlockersID(userID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the users who blocked the user from DB failed", err)
+	}
+	return func(client *chat.Client) bool {
+		return client.User == nil || !blockersID[client.User.ID]
+	}, nil
+}
+
+/*
+unsubscribes the client from the new comments of the post, when the full post view is closed
+*/
+func processClosePost(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	postID, err := parse.PayloadToInt(message.Payload)
+	if err != nil {
+		return errHelper(app, currConnection, fmt.Sprintf("Invalid payload for closing a post: '%s'", message.Payload), err)
+	}
+
+	if !currConnection.Client.ViewedPost.Leave(postID) {
+		return ignoreMessage(app, message, fmt.Sprintf("the post %d is not opened", postID))
+	}
+	return nil
+}
+
+/*
+sends the message to the clients of all the logged in users, except the client of the current connection.
+If 'isRecipient' is not nil, the message is sent only to the clients for which it returns true
+*/
+func sendMessageToLoggedClients(app *application.Application, currConnection *usersConnection, messageType string, data any, isRecipient func(*chat.Client) bool) error {
+	message, err := wsmodel.CreateMessage(messageType, "success", data)
+	if err != nil {
+		return errCreateMessage(app, currConnection, err)
+	}
+
+	wsMessage, err := json.Marshal(message)
+	if err != nil {
+		return errMarshalJSON(app, currConnection, err)
+	}
+
+	for _, clients := range app.Hub.GetOnlineUsers() {
+		for _, client := range clients {
+			if client == currConnection.Client || (isRecipient != nil && !isRecipient(client)) {
+				continue
+			}
+			client.WriteMessage(wsMessage)
+		}
+	}
+	return nil
+}
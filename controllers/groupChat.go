@@ -0,0 +1,391 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+func replyCreateGroupChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	groupChatData, err := parse.PayloadToGroupChat(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a new group chat: '%s'", message.Payload), err)
+	}
+
+	errmessage := groupChatData.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	// the creator is added to the chat anyway
+	membersID := make([]int, 0, len(groupChatData.MembersID))
+	for _, id := range groupChatData.MembersID {
+		if id != currConnection.session.User.ID {
+			membersID = append(membersID, id)
+		}
+	}
+	groupChatData.MembersID = membersID
+
+	for _, id := range groupChatData.MembersID {
+		_, err = getExistingUser(app, currConnection, id, message)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupChat, err := app.ForumData.CreateGroupChat(groupChatData.Title, currConnection.session.User.ID, groupChatData.MembersID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "creating a group chat failed", err)
+	}
+	app.InfoLog.Printf("group chat '%s' created with id %d", groupChat.Title, groupChat.ID)
+
+	err = sendGroupChatToMembers(app, currConnection, groupChat)
+	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		return nil, err
+	}
+
+	return groupChat, nil
+}
+
+func replyGroupChats(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	groupChats, err := app.ForumData.GetUsersGroupChats(currConnection.session.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the group chats from DB failed", err)
+	}
+	return groupChats, nil
+}
+
+func replyOpenGroupChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	chatID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || chatID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Can't open a group chat: invalid chatID '%s'", message.Payload), err)
+	}
+
+	groupChat, err := getGroupChatOfMember(app, currConnection, chatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addGroupChatMessages(app, currConnection, groupChat, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.MarkChatAsRead(chatID, currConnection.session.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "marking the chat messages as read failed", err)
+	}
+
+	currConnection.Client.OpenedChats.Open(chat.OpenedChat{
+		ChatID:   groupChat.ID,
+		ChatName: groupChat.Name,
+		Type:     model.GROUP_CHAT,
+	})
+
+	app.InfoLog.Printf("Group chat '%s' is opened by %s.", groupChat.Title, currConnection.session.User)
+	return groupChat, nil
+}
+
+func replyGroupChatPortion(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	portion, err := parse.PayloadToChatPortion(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for the portion of messages '%s'", message.Payload), err)
+	}
+
+	errmessage := portion.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	groupChat, err := getGroupChatOfMember(app, currConnection, portion.ChatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addGroupChatMessages(app, currConnection, groupChat, portion.BeforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupChat, nil
+}
+
+/*
+sends the message to the group chat, the chat may be not opened by the current user
+*/
+func replySendMessageToGroupChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	chatMessage, err := parse.PayloadToChatMessage(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a chat message: '%s'", message.Payload), err)
+	}
+
+	errmessage := chatMessage.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = checkAttachedImages(app, currConnection, message, chatMessage.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendMessageToGroupChat(app, currConnection, chatMessage, message)
+}
+
+/*
+saves the message to DB and sends it to all online members of the group chat given in the message.
+Used in replySendMessageToGroupChat and replySendMessageToOpendChat
+*/
+func sendMessageToGroupChat(app *application.Application, currConnection *usersConnection, chatMessage wsmodel.ChatMessage, message wsmodel.WSMessage) (any, error) {
+	groupChat, err := getGroupChatOfMember(app, currConnection, chatMessage.ChatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := app.ForumData.InsertChatMessage(groupChat.ID, currConnection.session.User.ID, chatMessage.MessageContent, chatMessage.Images, chatMessage.Date)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("save chat message in DB failed: '%s'", chatMessage.MessageContent), err)
+	}
+	chatMessage.ID = id
+	chatMessage.Author = currConnection.Client.User
+
+	err = stopTyping(app, currConnection, chatMessage.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	// offline members will get the message as unread when they come online
+	err = sendToGroupChatMembers(app, currConnection, groupChat.Members, wsmodel.InputChatMessage, chatMessage)
+	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		return nil, err
+	}
+
+	for _, member := range groupChat.Members {
+		if member.ID == currConnection.session.User.ID {
+			continue
+		}
+		if app.Hub.IsUserOnline(member.ID) {
+			err = setChatMessageDelivered(app, currConnection, chatMessage, member.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = setReadIfChatOpened(app, currConnection, member.ID, chatMessage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return chatMessage, nil
+}
+
+/*
+adds a user to the group chat. Any member of the chat can add new members
+*/
+func replyAddGroupChatMember(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	member, err := parseGroupChatMember(app, currConnection, message)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getGroupChatOfMember(app, currConnection, member.ChatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getExistingUser(app, currConnection, member.UserID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.AddChatMember(member.ChatID, member.UserID)
+	if errors.Is(err, model.ErrUnique) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d is already a member of the chat", member.UserID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "adding a member to the group chat failed", err)
+	}
+	app.InfoLog.Printf("user %d is added to the group chat %d by %s", member.UserID, member.ChatID, currConnection.session.User)
+
+	return updateGroupChatForMembers(app, currConnection, member.ChatID)
+}
+
+/*
+removes a user from the group chat. Only the creator of the chat can remove members
+*/
+func replyRemoveGroupChatMember(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	member, err := parseGroupChatMember(app, currConnection, message)
+	if err != nil {
+		return nil, err
+	}
+
+	groupChat, err := getGroupChatOfMember(app, currConnection, member.ChatID, message)
+	if err != nil {
+		return nil, err
+	}
+	if groupChat.CreatorID != currConnection.session.User.ID {
+		return nil, badRequestHelper(app, currConnection, message, "only the creator of the chat can remove members")
+	}
+	if member.UserID == currConnection.session.User.ID {
+		return nil, badRequestHelper(app, currConnection, message, "use leaving the chat to remove yourself")
+	}
+
+	err = app.ForumData.RemoveChatMember(member.ChatID, member.UserID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d is not a member of the chat", member.UserID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "removing a member from the group chat failed", err)
+	}
+	app.InfoLog.Printf("user %d is removed from the group chat %d by %s", member.UserID, member.ChatID, currConnection.session.User)
+
+	for _, removedClient := range app.Hub.GetUsersClients(member.UserID) {
+		removedClient.OpenedChats.Close(member.ChatID)
+	}
+
+	// the removed user has to know about removing too
+	removedUser := &model.User{ID: member.UserID}
+	return updateGroupChatForMembers(app, currConnection, member.ChatID, removedUser)
+}
+
+/*
+removes the current user from the group chat. If the user created the chat, the creator role goes to the member
+who has been in the chat the longest
+*/
+func replyLeaveGroupChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	chatID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || chatID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Can't leave a group chat: invalid chatID '%s'", message.Payload), err)
+	}
+
+	_, err = getGroupChatOfMember(app, currConnection, chatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = stopTyping(app, currConnection, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.LeaveGroupChat(chatID, currConnection.session.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "leaving the group chat failed", err)
+	}
+	app.InfoLog.Printf("%s left the group chat %d", currConnection.session.User, chatID)
+	for _, client := range app.Hub.GetUsersClients(currConnection.session.User.ID) {
+		client.OpenedChats.Close(chatID)
+	}
+
+	_, err = updateGroupChatForMembers(app, currConnection, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return chatID, nil
+}
+
+func parseGroupChatMember(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (wsmodel.GroupChatMember, error) {
+	member, err := parse.PayloadToGroupChatMember(message.Payload)
+	if err != nil {
+		return member, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a group chat member: '%s'", message.Payload), err)
+	}
+
+	errmessage := member.Validate()
+	if errmessage != "" {
+		return member, badRequestHelper(app, currConnection, message, errmessage)
+	}
+	return member, nil
+}
+
+/*
+returns the group chat with its members if the current user is an active member of the chat
+*/
+func getGroupChatOfMember(app *application.Application, currConnection *usersConnection, chatID int, message wsmodel.WSMessage) (*model.Chat, error) {
+	groupChat, err := app.ForumData.GetChat(chatID)
+	if errors.Is(err, model.ErrNoRecord) || (err == nil && groupChat.Type != model.GROUP_CHAT) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a group chat with id '%d'", chatID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the group chat from DB failed", err)
+	}
+
+	for _, member := range groupChat.Members {
+		if member.ID == currConnection.session.User.ID {
+			return groupChat, nil
+		}
+	}
+	return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("you are not a member of the group chat '%d'", chatID))
+}
+
+/*
+adds to the chat maximum CHAT_MESSAGES_PORTION messages with ids less than 'beforeID'
+*/
+func addGroupChatMessages(app *application.Application, currConnection *usersConnection, groupChat *model.Chat, beforeID int) error {
+	chatWithMessages, err := app.ForumData.GetPrivateChatMessagesByChatId(groupChat.ID, beforeID, CHAT_MESSAGES_PORTION)
+	if err != nil {
+		return errHelper(app, currConnection, "get the chat messages from DB failed", err)
+	}
+	groupChat.Messages = chatWithMessages.Messages
+	return nil
+}
+
+/*
+returns the user with the given id if the user exists in DB
+*/
+func getExistingUser(app *application.Application, currConnection *usersConnection, userID int, message wsmodel.WSMessage) (*model.User, error) {
+	user, err := app.ForumData.GetUserByID(userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("user with id %d doesn't exist", userID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the user with id %d from DB failed", userID), err)
+	}
+	return user, nil
+}
+
+/*
+gets the changed group chat from DB and sends it to its members and to the 'formerMembers'
+*/
+func updateGroupChatForMembers(app *application.Application, currConnection *usersConnection, chatID int, formerMembers ...*model.User) (*model.Chat, error) {
+	groupChat, err := app.ForumData.GetChat(chatID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the group chat from DB failed", err)
+	}
+
+	err = sendGroupChatToMembers(app, currConnection, groupChat, formerMembers...)
+	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		return nil, err
+	}
+	return groupChat, nil
+}
+
+/*
+sends the group chat data to its members and to the 'formerMembers', except the current client
+*/
+func sendGroupChatToMembers(app *application.Application, currConnection *usersConnection, groupChat *model.Chat, formerMembers ...*model.User) error {
+	recipients := append(append([]*model.User{}, groupChat.Members...), formerMembers...)
+	return sendToGroupChatMembers(app, currConnection, recipients, wsmodel.GroupChatUpdated, groupChat)
+}
+
+/*
+sends the message to all the devices of online 'members', except the current client
+*/
+func sendToGroupChatMembers(app *application.Application, currConnection *usersConnection, members []*model.User, messageType string, data any) error {
+	var errs error
+	for _, member := range members {
+		err := sendMessageToUser(app, currConnection, member.ID, messageType, data)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	if errs != nil {
+		return errors.Join(wsmodel.ErrWarning, errs)
+	}
+	return nil
+}
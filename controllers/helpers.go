@@ -121,9 +121,12 @@ func (uc *usersConnection) createNewClientAndSendUserOnline(app *application.App
 	return nil
 }
 
+/*
+unregisters the client, the user of the client becomes offline only if the client was the last client of the user
+*/
 func (uc *usersConnection) deleteClientAndSendUserOffline(app *application.Application, client *chat.Client) error {
-	app.Hub.UnRegisterFromHub(client)
-	if client.User != nil {
+	isLastClient := app.Hub.UnRegisterFromHub(client)
+	if isLastClient {
 		return sendOfflineUserToUsers(app, uc, client.User)
 	}
 	return nil
@@ -0,0 +1,222 @@
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	THUMBNAIL_SIZE = 200 // maximum width and height of a thumbnail in pixels
+	// images with a larger width or height are refused before decoding, so the decoded image and its RGBA copy take 64 MB each at most
+	MAX_SIDE = 4096
+)
+
+const thumbnailSuffix = "_thumb"
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+)
+
+// file extensions of the supported types, the type is detected by the content of a file
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var idPattern = regexp.MustCompile(`^[0-9a-f]{64}\.(jpg|png|gif)$`)
+
+/*
+Save checks the type of the image by sniffing its content and stores the image and its thumbnail in the dir.
+The name of the file is the SHA-256 hash of the content, so an image uploaded several times is stored once.
+Returns the id of the image, which is the file name
+*/
+func Save(dir string, data []byte) (string, error) {
+	ext, ok := extensions[http.DetectContentType(data)]
+	if !ok {
+		return "", ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnsupportedType, err)
+	}
+	if config.Width > MAX_SIDE || config.Height > MAX_SIDE {
+		return "", ErrTooLarge
+	}
+
+	hash := sha256.Sum256(data)
+	id := hex.EncodeToString(hash[:]) + ext
+	if Exists(dir, id) {
+		return id, nil
+	}
+
+	thumbnail, err := makeThumbnail(data, ext)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", err
+	}
+	// the thumbnail is written first, so an existing image always has a thumbnail
+	err = writeFile(filepath.Join(dir, ThumbnailID(id)), thumbnail)
+	if err != nil {
+		return "", err
+	}
+	err = writeFile(filepath.Join(dir, id), data)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+/*
+ThumbnailID returns the id of the thumbnail of the image.
+Thumbnails of JPEG images are JPEG, thumbnails of PNG and GIF images are PNG
+*/
+func ThumbnailID(id string) string {
+	name, ext, _ := strings.Cut(id, ".")
+	if ext != "jpg" {
+		ext = "png"
+	}
+	return name + thumbnailSuffix + "." + ext
+}
+
+// IsValidID reports whether the id has the form of the ids returned by Save
+func IsValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// Exists reports whether the image with the id is stored in the dir
+func Exists(dir, id string) bool {
+	if !IsValidID(id) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, id))
+	return err == nil && info.Mode().IsRegular()
+}
+
+/*
+decodes the image and returns its thumbnail encoded in the format of the thumbnail, see ThumbnailID
+*/
+func makeThumbnail(data []byte, ext string) ([]byte, error) {
+	var img image.Image
+	var err error
+	switch ext {
+	case ".jpg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case ".png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case ".gif":
+		img, err = gif.Decode(bytes.NewReader(data)) // the first frame of an animation
+	default:
+		return nil, ErrUnsupportedType
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedType, err)
+	}
+
+	thumbnail := scaleDown(img, THUMBNAIL_SIZE)
+
+	var buf bytes.Buffer
+	if ext == ".jpg" {
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 80})
+	} else {
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+/*
+scales the image down to fit into a square with the side 'size', keeping the proportions.
+Each pixel of the result is the average of the pixels of the source area it covers.
+The source is converted to RGBA once, the draw package does it without a call per pixel for the decoded types,
+then the average is counted on the bytes of the pixels.
+Images which fit into the square already are only copied
+*/
+func scaleDown(img image.Image, size int) *image.NRGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*h/th, max((y+1)*h/th, y*h/th+1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*w/tw, max((x+1)*w/tw, x*w/tw+1)
+
+			// the colors are premultiplied by alpha in RGBA, so the transparent pixels don't add their colors
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+					n++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r * 255 / a),
+				G: uint8(g * 255 / a),
+				B: uint8(b * 255 / a),
+				A: uint8(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+/*
+writes the file atomically: the data is written to a temporary file, which is renamed then
+*/
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,81 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 100))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 100; y++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := Save(dir, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsValidID(id) || filepath.Ext(id) != ".png" {
+		t.Fatalf("invalid id of the image: '%s'", id)
+	}
+	if !Exists(dir, id) {
+		t.Fatalf("the image '%s' is not stored", id)
+	}
+
+	thumbnailFile, err := os.Open(filepath.Join(dir, ThumbnailID(id)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer thumbnailFile.Close()
+	thumbnail, err := png.DecodeConfig(thumbnailFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumbnail.Width != THUMBNAIL_SIZE || thumbnail.Height != THUMBNAIL_SIZE/4 {
+		t.Errorf("thumbnail size is %dx%d, want %dx%d", thumbnail.Width, thumbnail.Height, THUMBNAIL_SIZE, THUMBNAIL_SIZE/4)
+	}
+
+	sameID, err := Save(dir, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sameID != id {
+		t.Errorf("the same image got different ids: '%s' and '%s'", id, sameID)
+	}
+
+	_, err = Save(dir, []byte("<html><body>not an image</body></html>"))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("saving a text file: got error %v, want %v", err, ErrUnsupportedType)
+	}
+
+	var largeBuf bytes.Buffer
+	err = png.Encode(&largeBuf, image.NewGray(image.Rect(0, 0, MAX_SIDE+1, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Save(dir, largeBuf.Bytes())
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("saving a too wide image: got error %v, want %v", err, ErrTooLarge)
+	}
+
+	for _, wrongID := range []string{"", "../data.db", "abc.png", ThumbnailID(id)} {
+		if Exists(dir, wrongID) {
+			t.Errorf("the id '%s' is accepted", wrongID)
+		}
+	}
+}
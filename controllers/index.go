@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"forum/application"
 	"forum/errorhandle"
@@ -60,6 +61,7 @@ func Index(app *application.Application) http.HandlerFunc {
 
 		// set the data to the view
 		viewVars["Posts"] = posts
+		viewVars["OAuthProviders"] = app.OAuthProviders
 
 		// render the view
 		if err = app.View.Execute(w, viewVars); err != nil {
@@ -73,13 +75,10 @@ func Index(app *application.Application) http.HandlerFunc {
 func IndexWs(app *application.Application) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		currentConnection := &usersConnection{}
+		currentConnection := &usersConnection{device: session.DeviceOf(app, r), lastSeenUpdate: time.Now(), closed: make(chan struct{})}
 
-		currentConnection.session, err = session.Get(app, w, r)
-		if err != nil {
-			errorhandle.ServerError(app, w, r, "getting a session failed", err)
-			return
-		}
+		// the session is read from DB and added to the context by the acl.AddUser middleware
+		currentConnection.session = r.Context().Value(acl.SessionKey).(*session.Session)
 
 		// TODO Erik: Delete later? Added it here to use Browsersync for easier frontend development
 		app.Upgrader.CheckOrigin = func(r *http.Request) bool {
@@ -112,6 +111,14 @@ func IndexWs(app *application.Application) http.HandlerFunc {
 			return
 		}
 
+		if currentConnection.session.IsLoggedin() {
+			err = sendUnreadChatMessages(app, currentConnection)
+			if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+				logErrorAndCloseConn(app, conn, "send unread chat messages failed", err)
+				return
+			}
+		}
+
 		// (online changes)
 		// if currentConnection.session.IsLoggedin() {
 		// 	err = sendOnlineUsers(app, currentConnection)
@@ -122,6 +129,7 @@ func IndexWs(app *application.Application) http.HandlerFunc {
 		// }
 
 		go currentConnection.ReadPump(app, w)
+		go currentConnection.SessionCheckPump(app)
 	}
 }
 
@@ -178,8 +186,44 @@ func getFilters(r *http.Request, viewVars *viewVarsMap, allCategories []*model.C
 	return
 }
 
+/*
+the session data sent to the client: the session with the summary of the user's unread chat messages,
+the email verification status and the "remember me" flag
+*/
+type indexSessionData struct {
+	*session.Session
+	UnreadChats []model.UnreadChat `json:"unreadChats,omitempty"`
+	Verified    bool               `json:"verified"` // the user confirmed the email
+	Remember    bool               `json:"remember"` // the session is kept after the browser is closed
+}
+
 func sendSession(app *application.Application, currConn *usersConnection) error {
-	return sendSuccessMessage(app, currConn, wsmodel.CurrentSession, currConn.session)
+	sessionData, err := createSessionData(app, currConn, currConn.session)
+	if err != nil {
+		return err
+	}
+	return sendSuccessMessage(app, currConn, wsmodel.CurrentSession, sessionData)
+}
+
+/*
+adds to the session the summary of unread chat messages and the verification status if a user is logged in
+*/
+func createSessionData(app *application.Application, currConn *usersConnection, sess *session.Session) (any, error) {
+	if !sess.IsLoggedin() {
+		return sess, nil
+	}
+
+	unreadChats, err := app.ForumData.GetUnreadChats(sess.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConn, "get unread chats from DB failed", err)
+	}
+
+	verified, err := app.ForumData.IsUserVerified(sess.User.ID)
+	if err != nil {
+		return nil, errHelper(app, currConn, "get the verification status of the user from DB failed", err)
+	}
+
+	return indexSessionData{Session: sess, UnreadChats: unreadChats, Verified: verified, Remember: sess.User.RememberSession}, nil
 }
 
 func logErrorAndCloseConn(app *application.Application, conn *websocket.Conn, errMessage string, err error) {
@@ -33,12 +33,26 @@ type Liker interface {
 }
 
 type LikesNumbers struct{
+	MessageType string `json:"messageType"` // model.POST or model.COMMENT
+	MessageID int `json:"messageID"`
 	Likes int `json:"likes"`
 	Dislikes int `json:"dislikes"`
 	UserWithReaction *model.User `json:"userWithReaction"`
 	UserReaction int8 `json:"userReactions"`
 }
 
+// LikesCounters are the numbers of reactions to a message without the reaction of the user, which can be sent to anyone
+type LikesCounters struct {
+	MessageType string `json:"messageType"`
+	MessageID   int    `json:"messageID"`
+	Likes       int    `json:"likes"`
+	Dislikes    int    `json:"dislikes"`
+}
+
+func (ln LikesNumbers) Counters() LikesCounters {
+	return LikesCounters{MessageType: ln.MessageType, MessageID: ln.MessageID, Likes: ln.Likes, Dislikes: ln.Dislikes}
+}
+
 func NewLikeComment(user *model.User, reactData wsmodel.Reaction) *LikeComment {
 	var lc LikeComment
 	lc.User = &model.User{ID: user.ID, Name: user.Name} 
@@ -67,6 +81,8 @@ func (pl *LikePost) GetLikesNumbers(db *sqlpkg.ForumModel) (LikesNumbers, error)
 	if err != nil {
 		return likesNum, err
 	}
+	likesNum.MessageType = model.POST
+	likesNum.MessageID = pl.MessageID
 	likesNum.Dislikes = likes[model.DISLIKE]
 	likesNum.Likes = likes[model.LIKE]
 	likesNum.UserReaction = userReaction
@@ -106,6 +122,8 @@ func (cl *LikeComment) GetLikesNumbers(db *sqlpkg.ForumModel) (LikesNumbers, err
 	if err != nil {
 		return likesNum, err
 	}
+	likesNum.MessageType = model.COMMENT
+	likesNum.MessageID = cl.MessageID
 	likesNum.Dislikes = likes[model.DISLIKE]
 	likesNum.Likes = likes[model.LIKE]
 	likesNum.UserReaction = userReaction
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+)
+
+// loginThrottle delays the logins after failures: the delay doubles with every failure and the logins are locked out
+// after too many failures
+type loginThrottle struct {
+	window       time.Duration // older failures are not counted
+	backoffAfter int           // number of failures allowed without a delay
+	lockoutAfter int           // number of failures, which lock the logins out
+	baseDelay    time.Duration // the delay after the first failure over backoffAfter
+	lockout      time.Duration // the logins are locked out during the time since the last failure
+}
+
+var (
+	// the attempts to log in to an account
+	accountThrottle = loginThrottle{
+		window:       time.Hour,
+		backoffAfter: 3,
+		lockoutAfter: 10,
+		baseDelay:    time.Second,
+		lockout:      15 * time.Minute,
+	}
+	// the attempts from an IP address, which can be shared by several users, to log in to any account
+	ipThrottle = loginThrottle{
+		window:       time.Hour,
+		backoffAfter: 10,
+		lockoutAfter: 50,
+		baseDelay:    time.Second,
+		lockout:      15 * time.Minute,
+	}
+)
+
+/*
+returns the time, when the next login is allowed after the failures given in the descending order.
+The zero time is returned if the login is allowed at once
+*/
+func (t loginThrottle) retryTime(failures []time.Time) time.Time {
+	n := len(failures)
+	if n < t.backoffAfter || n == 0 {
+		return time.Time{}
+	}
+	if n >= t.lockoutAfter {
+		return failures[0].Add(t.lockout)
+	}
+
+	delay := t.baseDelay
+	for i := t.backoffAfter; i < n && delay < t.lockout; i++ {
+		delay *= 2
+	}
+	if delay > t.lockout {
+		delay = t.lockout
+	}
+	return failures[0].Add(delay)
+}
+
+// isLockout reports whether the failures lock the logins out
+func (t loginThrottle) isLockout(failures []time.Time) bool {
+	return len(failures) >= t.lockoutAfter
+}
+
+/*
+returns an error if the logins from the connection's IP address are delayed or locked out,
+the loginReply error has the time, when the user can try again
+*/
+func checkLoginAllowedFromIP(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	now := time.Now()
+	failures, err := app.ForumData.GetLoginFailuresByIP(currConnection.device.IP, now.Add(-ipThrottle.window))
+	if err != nil {
+		return errHelper(app, currConnection, "getting the failed logins from DB failed", err)
+	}
+	return checkRetryTime(app, currConnection, message, ipThrottle.retryTime(failures), now)
+}
+
+/*
+returns an error if the logins to the user's account are delayed or locked out
+*/
+func checkLoginAllowedToAccount(app *application.Application, currConnection *usersConnection, user *model.User, message wsmodel.WSMessage) error {
+	now := time.Now()
+	failures, err := app.ForumData.GetLoginFailuresByUser(user.ID, now.Add(-accountThrottle.window))
+	if err != nil {
+		return errHelper(app, currConnection, "getting the failed logins from DB failed", err)
+	}
+	return checkRetryTime(app, currConnection, message, accountThrottle.retryTime(failures), now)
+}
+
+func checkRetryTime(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage, retryTime, now time.Time) error {
+	if !now.Before(retryTime) {
+		return nil
+	}
+	return badRequestHelper(app, currConnection, message,
+		fmt.Sprintf("Too many failed login attempts, try again after %s", retryTime.Format(time.RFC3339)))
+}
+
+/*
+keeps the failed login to the user's account from the connection's IP address, userID is 0 for unknown users.
+If the failure locks the account or the IP address out, the lockout is written to DB
+*/
+func registerLoginFailure(app *application.Application, currConnection *usersConnection, userID int) error {
+	now := time.Now()
+	ip := currConnection.device.IP
+	err := app.ForumData.InsertLoginFailure(userID, ip, now)
+	if err != nil {
+		return errHelper(app, currConnection, "adding the failed login to DB failed", err)
+	}
+
+	if userID > 0 {
+		failures, err := app.ForumData.GetLoginFailuresByUser(userID, now.Add(-accountThrottle.window))
+		if err != nil {
+			return errHelper(app, currConnection, "getting the failed logins from DB failed", err)
+		}
+		if accountThrottle.isLockout(failures) {
+			err = lockLoginsOut(app, userID, ip, failures, accountThrottle)
+			if err != nil {
+				return errHelper(app, currConnection, "adding the lockout to DB failed", err)
+			}
+		}
+	}
+
+	failures, err := app.ForumData.GetLoginFailuresByIP(ip, now.Add(-ipThrottle.window))
+	if err != nil {
+		return errHelper(app, currConnection, "getting the failed logins from DB failed", err)
+	}
+	if ipThrottle.isLockout(failures) {
+		err = lockLoginsOut(app, 0, ip, failures, ipThrottle)
+		if err != nil {
+			return errHelper(app, currConnection, "adding the lockout to DB failed", err)
+		}
+	}
+	return nil
+}
+
+func lockLoginsOut(app *application.Application, userID int, ip string, failures []time.Time, throttle loginThrottle) error {
+	retryTime := throttle.retryTime(failures)
+	err := app.ForumData.InsertLoginLockout(userID, ip, len(failures), failures[0], retryTime)
+	if err != nil {
+		return err
+	}
+	if userID > 0 {
+		app.InfoLog.Printf("the logins to the account of the user %d are locked out until %s after %d failures from %s", userID, retryTime.Format(time.RFC3339), len(failures), ip)
+	} else {
+		app.InfoLog.Printf("the logins from %s are locked out until %s after %d failures", ip, retryTime.Format(time.RFC3339), len(failures))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer sends emails to the users
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// SMTPMailer sends the emails through an SMTP server, the authentication is used if Username is set
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	err := smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, []string{to}, composeMessage(m.From, to, subject, body))
+	if err != nil {
+		return fmt.Errorf("sending the email to '%s' failed: %w", to, err)
+	}
+	return nil
+}
+
+// LogMailer writes the emails to the logger instead of sending them, it is used for local development and tests
+type LogMailer struct {
+	Log *log.Logger
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	m.Log.Printf("email to '%s', subject '%s':\n%s", to, subject, body)
+	return nil
+}
+
+func composeMessage(from, to, subject, body string) []byte {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(message.String())
+}
@@ -0,0 +1,29 @@
+package mailer
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	var m Mailer = &LogMailer{Log: log.New(&buf, "", 0)}
+
+	err := m.Send("user@forum.com", "Password reset", "the token is 123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "user@forum.com") || !strings.Contains(buf.String(), "the token is 123") {
+		t.Errorf("the email is not logged: %q", buf.String())
+	}
+
+	message := string(composeMessage("forum@forum.com", "user@forum.com", "Password reset", "line 1\nline 2"))
+	if !strings.HasPrefix(message, "From: forum@forum.com\r\nTo: user@forum.com\r\nSubject: Password reset\r\n") {
+		t.Errorf("wrong headers of the message: %q", message)
+	}
+	if !strings.HasSuffix(message, "\r\n\r\nline 1\r\nline 2") {
+		t.Errorf("wrong body of the message: %q", message)
+	}
+}
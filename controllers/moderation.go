@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/errorhandle"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+locks or unlocks a post, no new comments can be added to a locked post. The change is sent to all the other clients
+*/
+func replyLockPost(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	lockedPost, err := parse.PayloadToLockedPost(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for locking a post: '%s'", message.Payload), err)
+	}
+
+	errmessage := lockedPost.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = app.ForumData.LockPost(lockedPost.PostID, lockedPost.Locked)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a post with id '%d' or it is locked/unlocked already", lockedPost.PostID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "locking the post in DB failed", err)
+	}
+
+	action := model.MOD_LOCK_POST
+	if !lockedPost.Locked {
+		action = model.MOD_UNLOCK_POST
+	}
+	err = logModeration(app, currConnection, action, model.POST, lockedPost.PostID, lockedPost.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendMessageToLoggedClients(app, currConnection, wsmodel.PostLocked, lockedPost, nil)
+	if err != nil {
+		return nil, err
+	}
+	return lockedPost, nil
+}
+
+/*
+bans or unbans a user. A banned user is logged out on all the devices and cannot log in.
+Only users with a lower role can be banned
+*/
+func replyBanUser(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	bannedUser, err := parse.PayloadToBannedUser(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for banning a user: '%s'", message.Payload), err)
+	}
+
+	errmessage := bannedUser.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = checkRoleIsHigher(app, currConnection, bannedUser.UserID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	// the ban and its entry of the moderation log are written in one transaction
+	logEntry := newModerationLogEntry(currConnection, model.MOD_BAN_USER, model.USER, bannedUser.UserID, bannedUser.Reason)
+	if bannedUser.Banned {
+		err = app.ForumData.BanUser(bannedUser.UserID, logEntry.Date, logEntry)
+	} else {
+		logEntry.Action = model.MOD_UNBAN_USER
+		err = app.ForumData.UnbanUser(bannedUser.UserID, logEntry)
+	}
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the user %d is banned/unbanned already", bannedUser.UserID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "banning the user in DB failed", err)
+	}
+	printModeration(app, logEntry)
+
+	if bannedUser.Banned {
+		disconnectUser(app, bannedUser.UserID, "the account is banned")
+	}
+	return bannedUser, nil
+}
+
+/*
+suspends a user for the given number of minutes or lifts the suspension if the number is 0.
+A suspended user is logged out on all the devices, the connections of the user are closed
+*/
+func replySuspendUser(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	sanction, err := applySanction(app, currConnection, message, app.ForumData.SuspendUser, app.ForumData.UnsuspendUser,
+		model.MOD_SUSPEND_USER, model.MOD_UNSUSPEND_USER)
+	if err != nil {
+		return nil, err
+	}
+
+	if sanction.Until != nil {
+		disconnectUser(app, sanction.UserID, fmt.Sprintf("the account is suspended until %s", sanction.Until.Format(time.RFC3339)))
+	}
+	return sanction, nil
+}
+
+/*
+mutes a user for the given number of minutes or lifts the mute if the number is 0.
+A muted user can read but cannot post, comment or chat. The change is sent to the clients of the user
+*/
+func replyMuteUser(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	sanction, err := applySanction(app, currConnection, message, app.ForumData.MuteUser, app.ForumData.UnmuteUser,
+		model.MOD_MUTE_USER, model.MOD_UNMUTE_USER)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendMessageToUser(app, currConnection, sanction.UserID, wsmodel.UserMuted, sanction)
+	if err != nil {
+		return nil, err
+	}
+	return sanction, nil
+}
+
+/*
+imposes the sanction on the user from the payload or lifts it if the duration is 0, the action is added to the moderation log.
+Only users with a lower role can be sanctioned
+*/
+func applySanction(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage,
+	impose func(userID int, until time.Time, logEntry *model.ModerationLogEntry) error,
+	lift func(userID int, logEntry *model.ModerationLogEntry) error, imposeAction, liftAction string,
+) (wsmodel.UserSanction, error) {
+	sanction, err := parse.PayloadToUserSanction(message.Payload)
+	if err != nil {
+		return sanction, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a sanction: '%s'", message.Payload), err)
+	}
+
+	errmessage := sanction.Validate()
+	if errmessage != "" {
+		return sanction, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = checkRoleIsHigher(app, currConnection, sanction.UserID, message)
+	if err != nil {
+		return sanction, err
+	}
+
+	// the sanction and its entry of the moderation log are written in one transaction
+	logEntry := newModerationLogEntry(currConnection, liftAction, model.USER, sanction.UserID, sanction.Reason)
+	if sanction.Minutes == 0 {
+		err = lift(sanction.UserID, logEntry)
+	} else {
+		until := logEntry.Date.Add(time.Duration(sanction.Minutes) * time.Minute)
+		sanction.Until = &until
+		logEntry.Action = imposeAction
+		logEntry.Reason = fmt.Sprintf("until %s", until.Format(time.RFC3339))
+		if sanction.Reason != "" {
+			logEntry.Reason += ": " + sanction.Reason
+		}
+		err = impose(sanction.UserID, until, logEntry)
+	}
+	if errors.Is(err, model.ErrNoRecord) {
+		return sanction, badRequestHelper(app, currConnection, message, fmt.Sprintf("the user %d has no sanction to lift", sanction.UserID))
+	}
+	if err != nil {
+		return sanction, errHelper(app, currConnection, "changing the sanction of the user in DB failed", err)
+	}
+	printModeration(app, logEntry)
+	return sanction, nil
+}
+
+/*
+changes the role of a user, the current user cannot change the own role.
+Only the roles of users with a lower role can be changed
+*/
+func replySetUserRole(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	userRole, err := parse.PayloadToUserRole(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for changing a role: '%s'", message.Payload), err)
+	}
+
+	errmessage := userRole.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+	if userRole.UserID == currConnection.session.User.ID {
+		return nil, badRequestHelper(app, currConnection, message, "the own role cannot be changed")
+	}
+
+	err = checkRoleIsHigher(app, currConnection, userRole.UserID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.SetUserRole(userRole.UserID, userRole.Role)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a user with id '%d'", userRole.UserID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "changing the role in DB failed", err)
+	}
+
+	reason := fmt.Sprintf("new role '%s'", userRole.Role)
+	if userRole.Reason != "" {
+		reason += ": " + userRole.Reason
+	}
+	err = logModeration(app, currConnection, model.MOD_SET_ROLE, model.USER, userRole.UserID, reason)
+	if err != nil {
+		return nil, err
+	}
+	return userRole, nil
+}
+
+/*
+replies with a portion of the moderation log before the entry with the id given in the payload
+*/
+func replyModerationLog(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	beforeID, err := parse.PayloadToInt(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for the portion of the moderation log '%s'", message.Payload), err)
+	}
+
+	entries, err := app.ForumData.GetModerationLog(beforeID, MODERATION_LOG_PORTION)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the moderation log from DB failed", err)
+	}
+	return entries, nil
+}
+
+/*
+ModerationLog replies with a portion of the moderation log in JSON, the portion is given by the 'before' query parameter
+*/
+func ModerationLog(app *application.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		beforeID := 0
+		if before := r.URL.Query().Get("before"); before != "" {
+			var err error
+			beforeID, err = strconv.Atoi(before)
+			if err != nil {
+				errorhandle.ClientError(app, w, r, http.StatusBadRequest, fmt.Sprintf("invalid 'before' parameter: '%s'", before))
+				return
+			}
+		}
+
+		entries, err := app.ForumData.GetModerationLog(beforeID, MODERATION_LOG_PORTION)
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "getting the moderation log from DB failed", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	}
+}
+
+/*
+checks that the current user's role is at least 'role'. The role is read from DB, so a changed role takes effect right away
+*/
+func checkRole(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage, role model.Role) error {
+	hasRole, err := hasRole(app, currConnection, role)
+	if err != nil {
+		return err
+	}
+	if !hasRole {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("the role '%s' is required", role))
+	}
+	return nil
+}
+
+func hasRole(app *application.Application, currConnection *usersConnection, role model.Role) (bool, error) {
+	userRole, err := app.ForumData.GetUserRole(currConnection.session.User.ID)
+	if err != nil {
+		return false, errHelper(app, currConnection, "get the role of the user from DB failed", err)
+	}
+	return userRole.IsAtLeast(role), nil
+}
+
+/*
+checks that the current user's role is higher than the role of the user with the given id
+*/
+func checkRoleIsHigher(app *application.Application, currConnection *usersConnection, userID int, message wsmodel.WSMessage) error {
+	userRole, err := app.ForumData.GetUserRole(userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a user with id '%d'", userID))
+	}
+	if err != nil {
+		return errHelper(app, currConnection, "get the role of the user from DB failed", err)
+	}
+
+	currentRole, err := app.ForumData.GetUserRole(currConnection.session.User.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "get the role of the user from DB failed", err)
+	}
+	if !currentRole.IsHigherThan(userRole) {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("users with the role '%s' cannot be moderated by the role '%s'", userRole, currentRole))
+	}
+	return nil
+}
+
+/*
+closes the connections of all the user's clients, the reason is sent in the close frame
+*/
+func disconnectUser(app *application.Application, userID int, reason string) {
+	for _, client := range app.Hub.GetUsersClients(userID) {
+		disconnectClient(app, client, reason)
+	}
+}
+
+func disconnectClient(app *application.Application, client *chat.Client, reason string) {
+	err := client.Disconnect(reason)
+	if err != nil {
+		app.ErrLog.Printf("disconnecting the client %s failed: %v", client, err)
+		return
+	}
+	app.InfoLog.Printf("client %s is disconnected: %s", client, reason)
+}
+
+/*
+returns nil if the current user is not suspended. Otherwise closes the connection and returns an error
+*/
+func checkNotSuspended(app *application.Application, currConnection *usersConnection) error {
+	sanctions, err := getSanctions(app, currConnection)
+	if err != nil {
+		return err
+	}
+	if !sanctions.IsSuspended(time.Now()) {
+		return nil
+	}
+
+	reason := "the account is suspended"
+	if sanctions.Banned {
+		reason = "the account is banned"
+	}
+	disconnectUser(app, currConnection.session.User.ID, reason)
+	return errors.New(reason)
+}
+
+/*
+returns nil if the current user is not muted. Otherwise sends an error message and returns an error
+*/
+func checkNotMuted(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	sanctions, err := getSanctions(app, currConnection)
+	if err != nil {
+		return err
+	}
+	if sanctions.IsMuted(time.Now()) {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("the account is muted until %s", sanctions.MutedUntil.Format(time.RFC3339)))
+	}
+	return nil
+}
+
+func getSanctions(app *application.Application, currConnection *usersConnection) (model.Sanctions, error) {
+	sanctions, err := app.ForumData.GetUserSanctions(currConnection.session.User.ID)
+	if err != nil {
+		return sanctions, errHelper(app, currConnection, "get the sanctions of the user from DB failed", err)
+	}
+	return sanctions, nil
+}
+
+/*
+adds the action of the current user to the moderation log
+*/
+func logModeration(app *application.Application, currConnection *usersConnection, action, targetType string, targetID int, reason string) error {
+	logEntry := newModerationLogEntry(currConnection, action, targetType, targetID, reason)
+	_, err := app.ForumData.InsertModerationLog(logEntry.Moderator.ID, action, targetType, targetID, reason, logEntry.Date)
+	if err != nil {
+		return errHelper(app, currConnection, "adding to the moderation log failed", err)
+	}
+	printModeration(app, logEntry)
+	return nil
+}
+
+/*
+returns the entry of the moderation log for the action of the current user, which is dated now
+*/
+func newModerationLogEntry(currConnection *usersConnection, action, targetType string, targetID int, reason string) *model.ModerationLogEntry {
+	return &model.ModerationLogEntry{
+		Moderator:  currConnection.session.User,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Date:       time.Now(),
+	}
+}
+
+func printModeration(app *application.Application, logEntry *model.ModerationLogEntry) {
+	app.InfoLog.Printf("moderation: %s %s %d by %s", logEntry.Action, logEntry.TargetType, logEntry.TargetID, logEntry.Moderator)
+}
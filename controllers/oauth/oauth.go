@@ -0,0 +1,301 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// names of the supported providers
+const (
+	GITHUB = "github"
+	GOOGLE = "google"
+)
+
+const (
+	STATE_TTL        = 10 * time.Minute // the user has to come back from the provider during the time
+	maxResponseSize  = 1 << 20
+	randomDataLength = 32
+)
+
+// Provider is an OAuth2 provider, the endpoints can be changed, e.g. to a local fake provider in tests
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	EmailsURL    string // GitHub only: the list of the user's emails with the verification status
+	Scopes       []string
+	Client       *http.Client
+}
+
+// UserInfo is the account of a user at the provider
+type UserInfo struct {
+	ID            string
+	Login         string
+	Name          string
+	Email         string
+	EmailVerified bool
+}
+
+// NewGitHub returns the GitHub provider with the default endpoints
+func NewGitHub(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         GITHUB,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		EmailsURL:    "https://api.github.com/user/emails",
+		Scopes:       []string{"read:user", "user:email"},
+	}
+}
+
+// NewGoogle returns the Google provider with the default endpoints
+func NewGoogle(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         GOOGLE,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+/*
+returns the address of the provider's authorization page, the PKCE challenge is created from the verifier
+*/
+func (p *Provider) AuthCodeURL(state, verifier string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"scope":                 {strings.Join(p.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.AuthURL + separator + params.Encode()
+}
+
+/*
+exchanges the authorization code for an access token
+*/
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	params := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	err = p.do(request, &token)
+	if err != nil {
+		return "", fmt.Errorf("getting the access token from %s failed: %w", p.Name, err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("getting the access token from %s failed: %s %s", p.Name, token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("getting the access token from %s failed: empty token", p.Name)
+	}
+	return token.AccessToken, nil
+}
+
+/*
+returns the account of the user who granted the access token
+*/
+func (p *Provider) GetUser(ctx context.Context, accessToken string) (*UserInfo, error) {
+	if p.Name == GITHUB {
+		return p.getGitHubUser(ctx, accessToken)
+	}
+	return p.getOpenIDUser(ctx, accessToken)
+}
+
+func (p *Provider) getOpenIDUser(ctx context.Context, accessToken string) (*UserInfo, error) {
+	var user struct {
+		Sub           string `json:"sub"`
+		Name          string `json:"name"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	err := p.get(ctx, p.UserInfoURL, accessToken, &user)
+	if err != nil {
+		return nil, err
+	}
+	if user.Sub == "" {
+		return nil, fmt.Errorf("the user of %s has no id", p.Name)
+	}
+
+	login, _, _ := strings.Cut(user.Email, "@")
+	return &UserInfo{ID: user.Sub, Login: login, Name: user.Name, Email: user.Email, EmailVerified: user.EmailVerified}, nil
+}
+
+func (p *Provider) getGitHubUser(ctx context.Context, accessToken string) (*UserInfo, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	err := p.get(ctx, p.UserInfoURL, accessToken, &user)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("the user of %s has no id", p.Name)
+	}
+	info := &UserInfo{ID: strconv.FormatInt(user.ID, 10), Login: user.Login, Name: user.Name}
+
+	// the public email of the user may be not verified, so the primary verified email is taken from the list of emails
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	err = p.get(ctx, p.EmailsURL, accessToken, &emails)
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			info.Email = email.Email
+			info.EmailVerified = true
+		}
+	}
+	return info, nil
+}
+
+func (p *Provider) get(ctx context.Context, url, accessToken string, data any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	err = p.do(request, data)
+	if err != nil {
+		return fmt.Errorf("getting the user from %s failed: %w", p.Name, err)
+	}
+	return nil
+}
+
+func (p *Provider) do(request *http.Request, data any) error {
+	request.Header.Set("Accept", "application/json")
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %s: %s", response.Status, body)
+	}
+	return json.Unmarshal(body, data)
+}
+
+// NewRandomString returns a random string for a state or a PKCE verifier
+func NewRandomString() (string, error) {
+	data := make([]byte, randomDataLength)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// challenge returns the PKCE S256 challenge of the verifier (RFC 7636)
+func challenge(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+type pendingLogin struct {
+	provider string
+	verifier string
+	expiry   time.Time
+}
+
+// States keeps the started logins until the users come back from the providers, a state can be taken once
+type States struct {
+	mu     sync.Mutex
+	logins map[string]pendingLogin
+}
+
+func NewStates() *States {
+	return &States{logins: make(map[string]pendingLogin)}
+}
+
+/*
+keeps the PKCE verifier of the login started with the provider, the expired logins are deleted
+*/
+func (s *States) Add(state, provider, verifier string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, login := range s.logins {
+		if now.After(login.expiry) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = pendingLogin{provider: provider, verifier: verifier, expiry: now.Add(STATE_TTL)}
+}
+
+/*
+returns the PKCE verifier of the login and deletes the login.
+Returns false if there is no such login with the provider or the login is expired
+*/
+func (s *States) Take(state, provider string, now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.logins[state]
+	if !ok {
+		return "", false
+	}
+	delete(s.logins, state)
+	if login.provider != provider || now.After(login.expiry) {
+		return "", false
+	}
+	return login.verifier, true
+}
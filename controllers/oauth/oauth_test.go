@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// fakeGitHub is a local provider, which accepts the code "the code" with the PKCE verifier of the test
+func fakeGitHub(t *testing.T, verifier string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "the code" || challenge(r.FormValue("code_verifier")) != challenge(verifier) || r.FormValue("client_secret") != "secret" {
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "the token"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer the token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 42, "login": "octocat", "name": "The Octocat"})
+	})
+	mux.HandleFunc("/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "public@example.com", "primary": false, "verified": false},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProvider(t *testing.T) {
+	verifier, err := NewRandomString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := fakeGitHub(t, verifier)
+
+	p := NewGitHub("client", "secret", "http://localhost:8080/login/github/callback")
+	p.AuthURL = server.URL + "/authorize"
+	p.TokenURL = server.URL + "/token"
+	p.UserInfoURL = server.URL + "/user"
+	p.EmailsURL = server.URL + "/emails"
+
+	authURL, err := url.Parse(p.AuthCodeURL("the state", verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authURL.Query()
+	if query.Get("state") != "the state" || query.Get("code_challenge") != challenge(verifier) || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("wrong authorization URL: %s", authURL)
+	}
+
+	ctx := context.Background()
+	_, err = p.Exchange(ctx, "the code", "wrong verifier")
+	if err == nil {
+		t.Errorf("the code is exchanged with a wrong PKCE verifier")
+	}
+	token, err := p.Exchange(ctx, "the code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := p.GetUser(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UserInfo{ID: "42", Login: "octocat", Name: "The Octocat", Email: "octocat@example.com", EmailVerified: true}
+	if *user != want {
+		t.Errorf("got the user %+v, want %+v", *user, want)
+	}
+}
+
+func TestStates(t *testing.T) {
+	states := NewStates()
+	now := time.Now()
+
+	states.Add("state", GITHUB, "verifier", now)
+	if _, ok := states.Take("state", GOOGLE, now); ok {
+		t.Errorf("the state is taken for another provider")
+	}
+
+	states.Add("state", GITHUB, "verifier", now)
+	if _, ok := states.Take("state", GITHUB, now.Add(STATE_TTL+time.Second)); ok {
+		t.Errorf("an expired state is taken")
+	}
+
+	states.Add("state", GITHUB, "verifier", now)
+	verifier, ok := states.Take("state", GITHUB, now)
+	if !ok || verifier != "verifier" {
+		t.Errorf("got the verifier '%s', %t, want 'verifier'", verifier, ok)
+	}
+	if _, ok := states.Take("state", GITHUB, now); ok {
+		t.Errorf("the state is taken twice")
+	}
+}
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"forum/application"
+	"forum/controllers/oauth"
+	"forum/errorhandle"
+	"forum/model"
+	"forum/session"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	OAUTH_STATE_COOKIE    = "forum_oauth_state"
+	OAUTH_REQUEST_TIMEOUT = 10 * time.Second // the time to get the token and the account from the provider
+)
+
+var errOAuthLogin = errors.New("the account of the provider cannot be used to log in")
+
+// OAuthGitHub starts the login with GitHub
+func OAuthGitHub(app *application.Application) http.HandlerFunc {
+	return oauthLogin(app, oauth.GITHUB)
+}
+
+// OAuthGitHubCallback finishes the login with GitHub
+func OAuthGitHubCallback(app *application.Application) http.HandlerFunc {
+	return oauthCallback(app, oauth.GITHUB)
+}
+
+// OAuthGoogle starts the login with Google
+func OAuthGoogle(app *application.Application) http.HandlerFunc {
+	return oauthLogin(app, oauth.GOOGLE)
+}
+
+// OAuthGoogleCallback finishes the login with Google
+func OAuthGoogleCallback(app *application.Application) http.HandlerFunc {
+	return oauthCallback(app, oauth.GOOGLE)
+}
+
+/*
+redirects the user to the authorization page of the provider. The state is kept on the server with the PKCE verifier
+and in a cookie, to be sure the callback comes to the same browser
+*/
+func oauthLogin(app *application.Application, providerName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, ok := app.OAuthProviders[providerName]
+		if !ok {
+			errorhandle.NotFound(app, w, r)
+			return
+		}
+
+		state, err := oauth.NewRandomString()
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "creating the OAuth state failed", err)
+			return
+		}
+		verifier, err := oauth.NewRandomString()
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "creating the PKCE verifier failed", err)
+			return
+		}
+		app.OAuthStates.Add(state, providerName, verifier, time.Now())
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     OAUTH_STATE_COOKIE,
+			Value:    state,
+			Path:     "/login/" + providerName,
+			MaxAge:   int(oauth.STATE_TTL.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, provider.AuthCodeURL(state, verifier), http.StatusFound)
+	}
+}
+
+/*
+checks the state, gets the user's account from the provider, logs the user in and redirects to the main page
+*/
+func oauthCallback(app *application.Application, providerName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, ok := app.OAuthProviders[providerName]
+		if !ok {
+			errorhandle.NotFound(app, w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		state := query.Get("state")
+		cookie, err := r.Cookie(OAUTH_STATE_COOKIE)
+		if err != nil || state == "" || cookie.Value != state {
+			errorhandle.ClientError(app, w, r, http.StatusBadRequest, fmt.Sprintf("login with %s: the state is invalid", providerName))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: OAUTH_STATE_COOKIE, Path: "/login/" + providerName, MaxAge: -1})
+
+		verifier, ok := app.OAuthStates.Take(state, providerName, time.Now())
+		if !ok {
+			errorhandle.ClientError(app, w, r, http.StatusBadRequest, fmt.Sprintf("login with %s: the state is invalid or expired", providerName))
+			return
+		}
+		if errMessage := query.Get("error"); errMessage != "" {
+			errorhandle.ClientError(app, w, r, http.StatusUnauthorized, fmt.Sprintf("login with %s is refused: %s", providerName, errMessage))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), OAUTH_REQUEST_TIMEOUT)
+		defer cancel()
+		token, err := provider.Exchange(ctx, query.Get("code"), verifier)
+		if err != nil {
+			app.ErrLog.Println(err)
+			errorhandle.ClientError(app, w, r, http.StatusUnauthorized, fmt.Sprintf("login with %s failed", providerName))
+			return
+		}
+		account, err := provider.GetUser(ctx, token)
+		if err != nil {
+			errorhandle.ServerError(app, w, r, fmt.Sprintf("getting the account from %s failed", providerName), err)
+			return
+		}
+
+		user, err := getOAuthUser(app, providerName, account)
+		if errors.Is(err, errOAuthLogin) {
+			errorhandle.ClientError(app, w, r, http.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			errorhandle.ServerError(app, w, r, fmt.Sprintf("getting the user of the %s account failed", providerName), err)
+			return
+		}
+		if user.IsSuspended(time.Now()) {
+			errorhandle.ClientError(app, w, r, http.StatusForbidden, fmt.Sprintf("User '%s' is banned or suspended", user.Name))
+			return
+		}
+		// the provider's login is only the first factor, and the second one cannot be asked in the redirect
+		twoFactor, err := isTwoFactorEnabled(app, user.ID)
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "checking the two-factor authentication failed", err)
+			return
+		}
+		if twoFactor {
+			errorhandle.ClientError(app, w, r, http.StatusForbidden, fmt.Sprintf("User '%s' has two-factor authentication enabled, log in with the password and the code", user.Name))
+			return
+		}
+
+		_, err = session.New(app, w, user, session.DeviceOf(app, r), false)
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "session creation failed", err)
+			return
+		}
+		app.InfoLog.Printf("User '%s' logged in with %s", user.Name, providerName)
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}
+}
+
+/*
+returns the user linked to the account of the provider.
+If there is no link yet, the account is linked to the user with the same email or to a new user.
+Only a verified email is trusted, and an existing user has to have the email verified too,
+otherwise anyone could register the email here and take the account of the provider's user
+*/
+func getOAuthUser(app *application.Application, providerName string, account *oauth.UserInfo) (*model.User, error) {
+	userID, err := app.ForumData.GetUserIDByOAuthAccount(providerName, account.ID)
+	if err == nil {
+		return app.ForumData.GetUserByID(userID)
+	}
+	if !errors.Is(err, model.ErrNoRecord) {
+		return nil, err
+	}
+
+	if account.Email == "" || !account.EmailVerified {
+		return nil, fmt.Errorf("%w: the email of the account is not verified by %s", errOAuthLogin, providerName)
+	}
+
+	user, err := app.ForumData.GetUserByEmail(account.Email)
+	switch {
+	case err == nil:
+		if !user.Verified {
+			return nil, fmt.Errorf("%w: the user with the email %s exists, but the email is not verified", errOAuthLogin, account.Email)
+		}
+	case errors.Is(err, model.ErrNoRecord):
+		user, err = createOAuthUser(app, account)
+		if err != nil {
+			return nil, err
+		}
+		app.InfoLog.Printf("User '%s'--'%s' signed up with %s", user.Name, user.Email, providerName)
+	default:
+		return nil, err
+	}
+
+	err = app.ForumData.LinkOAuthAccount(user.ID, providerName, account.ID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("linking the %s account to the user %d failed: %w", providerName, user.ID, err)
+	}
+	return user, nil
+}
+
+/*
+adds a verified user with the email of the account. The name is the login at the provider,
+a number is added to it if the name is taken. The user cannot log in with a password until they reset it
+*/
+func createOAuthUser(app *application.Application, account *oauth.UserInfo) (*model.User, error) {
+	randomPassword, err := oauth.NewRandomString()
+	if err != nil {
+		return nil, err
+	}
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(account.Name), " ")
+	user := &model.User{
+		Email:      account.Email,
+		DateCreate: time.Now(),
+		FirstName:  firstName,
+		LastName:   strings.TrimSpace(lastName),
+		Verified:   true,
+	}
+	user.Password, err = bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate crypto password: %w", err)
+	}
+
+	user.Name, err = getFreeUserName(app, account.Login)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ID, err = app.ForumData.AddUser(user)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("adding the user '%s' to DB failed", user.Name)
+	}
+	return user, nil
+}
+
+func getFreeUserName(app *application.Application, login string) (string, error) {
+	if login == "" {
+		login = "user"
+	}
+	name := login
+	for i := 1; ; i++ {
+		err := app.ForumData.CheckUserByName(name)
+		if errors.Is(err, model.ErrNoRecord) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		name = login + strconv.Itoa(i)
+	}
+}
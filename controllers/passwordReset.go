@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"forum/application"
+	"forum/errorhandle"
+	"forum/model"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	RESET_TOKEN_TTL       = 30 * time.Minute // a password reset token can be used during the time only
+	RESET_REQUESTS_LIMIT  = 3                // maximum number of password reset emails sent to an email during RESET_REQUESTS_PERIOD
+	RESET_REQUESTS_PERIOD = time.Hour
+)
+
+const secretTokenLength = 32
+
+/*
+RequestPasswordReset sends an email with a password reset token to the email given in the form field F_EMAIL.
+The reply is sent before the user is looked up, so neither its content nor its time tells if there is a user
+with the email
+*/
+func RequestPasswordReset(app *application.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address, err := mail.ParseAddress(r.FormValue(F_EMAIL))
+		if err != nil {
+			passwordResetError(app, w, http.StatusBadRequest, "wrong email")
+			return
+		}
+
+		go sendPasswordReset(app, address.Address)
+		passwordResetSuccess(w)
+	}
+}
+
+/*
+creates a password reset token for the user with the email and sends it to the email.
+The emails over RESET_REQUESTS_LIMIT are not sent
+*/
+func sendPasswordReset(app *application.Application, email string) {
+	user, err := app.ForumData.GetUserByEmail(email)
+	if errors.Is(err, model.ErrNoRecord) {
+		app.InfoLog.Printf("password reset is requested for the unknown email '%s'", email)
+		return
+	}
+	if err != nil {
+		app.ErrLog.Printf("getting the user from DB for the password reset failed: %v", err)
+		return
+	}
+
+	now := time.Now()
+	count, err := app.ForumData.CountPasswordResets(user.ID, now.Add(-RESET_REQUESTS_PERIOD))
+	if err != nil {
+		app.ErrLog.Printf("counting the password resets in DB failed: %v", err)
+		return
+	}
+	if count >= RESET_REQUESTS_LIMIT {
+		app.InfoLog.Printf("too many password resets are requested for the user %s", user)
+		return
+	}
+
+	token, err := createSecretToken()
+	if err != nil {
+		app.ErrLog.Printf("creating the password reset token failed: %v", err)
+		return
+	}
+	err = app.ForumData.InsertPasswordReset(user.ID, hashSecretToken(token), now, now.Add(RESET_TOKEN_TTL))
+	if err != nil {
+		app.ErrLog.Printf("adding the password reset to DB failed: %v", err)
+		return
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nto set a new password of your forum account follow the link:\n%s/?resetToken=%s\n\n"+
+		"The link expires in %d minutes. If you didn't request a password reset, ignore this email.",
+		user.Name, app.BaseURL, token, int(RESET_TOKEN_TTL.Minutes()))
+	err = app.Mailer.Send(user.Email, "Password reset", body)
+	if err != nil {
+		app.ErrLog.Printf("sending the password reset email to the user %s failed: %v", user, err)
+		return
+	}
+
+	app.InfoLog.Printf("password reset email is sent to the user %s", user)
+}
+
+/*
+ConfirmPasswordReset sets the password given in the form field F_PASSWORD for the owner of the token given in F_TOKEN.
+The token can be used once. All the sessions of the user are deleted and the user's clients are disconnected
+*/
+func ConfirmPasswordReset(app *application.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.FormValue(F_TOKEN)
+		password := r.FormValue(F_PASSWORD)
+		if token == "" {
+			passwordResetError(app, w, http.StatusBadRequest, "token missing")
+			return
+		}
+		if password == "" {
+			passwordResetError(app, w, http.StatusBadRequest, "password missing")
+			return
+		}
+
+		hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "password crypting failed", err)
+			return
+		}
+
+		userID, err := app.ForumData.ResetPassword(hashSecretToken(token), hashPassword, time.Now())
+		if errors.Is(err, model.ErrNoRecord) {
+			passwordResetError(app, w, http.StatusBadRequest, "the token is invalid, used or expired")
+			return
+		}
+		if err != nil {
+			errorhandle.ServerError(app, w, r, "resetting the password in DB failed", err)
+			return
+		}
+
+		disconnectUser(app, userID, "the password is reset")
+		app.InfoLog.Printf("the password of the user %d is reset", userID)
+		passwordResetSuccess(w)
+	}
+}
+
+func createSecretToken() (string, error) {
+	token := make([]byte, secretTokenLength)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+/*
+only the hashes of the password reset and email verification tokens are kept in DB,
+so the tokens cannot be used if DB is leaked
+*/
+func hashSecretToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func passwordResetSuccess(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+func passwordResetError(app *application.Application, w http.ResponseWriter, status int, errMessage string) {
+	app.InfoLog.Printf("password reset failed: %s", errMessage)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"status": "failure", "error": errMessage})
+}
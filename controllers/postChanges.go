@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+changes the theme and the content of the current user's post, the previous version is kept in the edit history.
+The change is sent to all the other clients
+*/
+func replyEditPost(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	editedPost, err := parse.PayloadToEditedPost(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for editing a post: '%s'", message.Payload), err)
+	}
+
+	errmessage := editedPost.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = checkOwnPost(app, currConnection, editedPost.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	editedPost.DateEdit = time.Now()
+
+	err = app.ForumData.EditPost(editedPost.ID, editedPost.Theme, editedPost.Content, editedPost.DateEdit)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "editing the post in DB failed", err)
+	}
+	app.InfoLog.Printf("post %d is edited by %s", editedPost.ID, currConnection.session.User)
+
+	err = sendMessageToLoggedClients(app, currConnection, wsmodel.PostEdited, editedPost, nil)
+	if err != nil {
+		return nil, err
+	}
+	return editedPost, nil
+}
+
+/*
+deletes the post with its comments and likes. Moderators can delete posts of other users, such deletions are added to the moderation log.
+The deletion is sent to all the other clients
+*/
+func replyDeletePost(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	deletedPost, err := parse.PayloadToDeletedPost(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for deleting a post: '%s'", message.Payload), err)
+	}
+
+	errmessage := deletedPost.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	authorID, err := getPostAuthorID(app, currConnection, deletedPost.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	isModeration, err := checkDeleteRights(app, currConnection, authorID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.DeletePost(deletedPost.ID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the post %d is deleted already", deletedPost.ID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "deleting the post in DB failed", err)
+	}
+	app.InfoLog.Printf("post %d is deleted by %s", deletedPost.ID, currConnection.session.User)
+	currConnection.Client.ViewedPost.Leave(deletedPost.ID)
+	if isModeration {
+		err = logModeration(app, currConnection, model.MOD_DELETE_POST, model.POST, deletedPost.ID, deletedPost.Reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = sendMessageToLoggedClients(app, currConnection, wsmodel.PostDeleted, deletedPost, nil)
+	if err != nil {
+		return nil, err
+	}
+	return deletedPost, nil
+}
+
+/*
+changes the content of the current user's comment, the previous version is kept in the edit history.
+The change is sent to the other clients viewing the post of the comment
+*/
+func replyEditComment(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	editedComment, err := parse.PayloadToEditedComment(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for editing a comment: '%s'", message.Payload), err)
+	}
+
+	errmessage := editedComment.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	editedComment.PostID, err = getOwnCommentPost(app, currConnection, editedComment.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	editedComment.DateEdit = time.Now()
+
+	err = app.ForumData.EditComment(editedComment.ID, editedComment.Content, editedComment.DateEdit)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "editing the comment in DB failed", err)
+	}
+	app.InfoLog.Printf("comment %d is edited by %s", editedComment.ID, currConnection.session.User)
+
+	err = sendToPostViewers(app, currConnection, editedComment.PostID, wsmodel.CommentEdited, editedComment)
+	if err != nil {
+		return nil, err
+	}
+	return editedComment, nil
+}
+
+/*
+deletes the comment with its likes. Moderators can delete comments of other users, such deletions are added to the moderation log.
+The deletion is sent to the other clients viewing the post of the comment
+*/
+func replyDeleteComment(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	deletedComment, err := parse.PayloadToDeletedComment(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for deleting a comment: '%s'", message.Payload), err)
+	}
+
+	errmessage := deletedComment.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	comment, err := getComment(app, currConnection, deletedComment.ID, message)
+	if err != nil {
+		return nil, err
+	}
+	deletedComment.PostID = comment.PostID
+	isModeration, err := checkDeleteRights(app, currConnection, comment.Message.Author.ID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.ForumData.Delete(deletedComment.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "deleting the comment in DB failed", err)
+	}
+	app.InfoLog.Printf("comment %d is deleted by %s", deletedComment.ID, currConnection.session.User)
+	if isModeration {
+		err = logModeration(app, currConnection, model.MOD_DELETE_COMMENT, model.COMMENT, deletedComment.ID, deletedComment.Reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = sendToPostViewers(app, currConnection, deletedComment.PostID, wsmodel.CommentDeleted, deletedComment)
+	if err != nil {
+		return nil, err
+	}
+	return deletedComment, nil
+}
+
+/*
+checks if the current user is the author of the post
+*/
+func checkOwnPost(app *application.Application, currConnection *usersConnection, postID int, message wsmodel.WSMessage) error {
+	authorID, err := getPostAuthorID(app, currConnection, postID, message)
+	if err != nil {
+		return err
+	}
+
+	if authorID != currConnection.session.User.ID {
+		return badRequestHelper(app, currConnection, message, "only the author can change the post")
+	}
+	return nil
+}
+
+/*
+returns the id of the post's author. Unlike getPost, it finds the posts of the users blocked by the current user too,
+so a moderator can delete them
+*/
+func getPostAuthorID(app *application.Application, currConnection *usersConnection, postID int, message wsmodel.WSMessage) (int, error) {
+	_, authorID, err := app.ForumData.GetMessagePostAndAuthor(model.POST, postID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return 0, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a post with id '%d'", postID))
+	}
+	if err != nil {
+		return 0, errHelper(app, currConnection, "get the post from DB failed", err)
+	}
+	return authorID, nil
+}
+
+/*
+returns the post id of the comment, if the current user is the author of the comment
+*/
+func getOwnCommentPost(app *application.Application, currConnection *usersConnection, commentID int, message wsmodel.WSMessage) (int, error) {
+	comment, err := getComment(app, currConnection, commentID, message)
+	if err != nil {
+		return 0, err
+	}
+
+	if comment.Message.Author.ID != currConnection.session.User.ID {
+		return 0, badRequestHelper(app, currConnection, message, "only the author can change the comment")
+	}
+	return comment.PostID, nil
+}
+
+func getComment(app *application.Application, currConnection *usersConnection, commentID int, message wsmodel.WSMessage) (*model.Comment, error) {
+	comment, err := app.ForumData.GetCommentByID(commentID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a comment with id '%d'", commentID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the comment from DB failed", err)
+	}
+	return comment, nil
+}
+
+/*
+checks if the current user can delete a post or a comment of the author: the own ones or as a moderator.
+Returns true if it is a moderation, i.e. the current user is not the author
+*/
+func checkDeleteRights(app *application.Application, currConnection *usersConnection, authorID int, message wsmodel.WSMessage) (bool, error) {
+	if authorID == currConnection.session.User.ID {
+		return false, nil
+	}
+
+	err := checkRole(app, currConnection, message, model.ROLE_MODERATOR)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+sends the message to the other clients which are viewing the post
+*/
+func sendToPostViewers(app *application.Application, currConnection *usersConnection, postID int, messageType string, data any) error {
+	isViewingPost := func(client *chat.Client) bool {
+		return client.ViewedPost.IsViewing(postID)
+	}
+	return sendMessageToLoggedClients(app, currConnection, messageType, data, isViewingPost)
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 
 	"forum/application"
+	"forum/model"
 	"forum/wsmodel"
 	"forum/wsmodel/parse"
 )
@@ -22,7 +24,28 @@ func replyNewComment(app *application.Application, currConnection *usersConnecti
 		return nil, badRequestHelper(app, currConnection, message, errmessage)
 	}
 
-	err = saveCommentToDB(app, currConnection, comment, currConnection.session.User.ID)
+	err = checkAttachedImages(app, currConnection, message, comment.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := app.ForumData.IsPostLocked(comment.PostID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a post with id '%d'", comment.PostID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "get the post from DB failed", err)
+	}
+	if locked {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the post %d is locked, new comments cannot be added", comment.PostID))
+	}
+
+	id, err := saveCommentToDB(app, currConnection, comment, currConnection.session.User.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendCommentCreated(app, currConnection, id)
 	if err != nil {
 		return nil, err
 	}
@@ -31,20 +54,21 @@ func replyNewComment(app *application.Application, currConnection *usersConnecti
 	if err != nil {
 		return nil, err
 	}
+	currConnection.Client.ViewedPost.View(post.ID)
 	
 	return post, nil
 }
 
-func saveCommentToDB(app *application.Application, currConnection *usersConnection, comment wsmodel.Comment, authorID int) error {
+func saveCommentToDB(app *application.Application, currConnection *usersConnection, comment wsmodel.Comment, authorID int) (int, error) {
 	dateCreate := comment.Date
 
-	id, err := app.ForumData.InsertComment(comment.PostID, comment.Content, nil, authorID, dateCreate)
+	id, err := app.ForumData.InsertComment(comment.PostID, comment.Content, comment.Images, authorID, dateCreate)
 	if err != nil {
-		return errHelper(app, currConnection, "insert a new comment to DB failed", err)
+		return 0, errHelper(app, currConnection, "insert a new comment to DB failed", err)
 	}
 
 	app.InfoLog.Printf("A comment is added to DB. id: '%d'", id)
-	return nil
+	return id, nil
 }
 
 // Delete the comment -not WS version
@@ -23,7 +23,17 @@ func replyNewPost(app *application.Application, currConnection *usersConnection,
 		return nil, badRequestHelper(app, currConnection, message, errmessage)
 	}
 
-	err = savePostToDB(app, currConnection, postData)
+	err = checkAttachedImages(app, currConnection, message, postData.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := savePostToDB(app, currConnection, postData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendPostCreated(app, currConnection, id)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +46,7 @@ func replyNewPost(app *application.Application, currConnection *usersConnection,
 	return posts, nil
 }
 
-func savePostToDB(app *application.Application, currConnection *usersConnection, postData wsmodel.Post) error {
+func savePostToDB(app *application.Application, currConnection *usersConnection, postData wsmodel.Post) (int, error) {
 	dateCreate := postData.Date
 
 	// check if categories ids are valid (exist in DB)
@@ -44,18 +54,18 @@ func savePostToDB(app *application.Application, currConnection *usersConnection,
 		_, err := app.ForumData.GetCategoryByID(id)
 		if err != nil {
 			if errors.Is(err, model.ErrNoRecord) {
-				return errHelper(app, currConnection, fmt.Sprintf("no cathegory with id: '%d' in DB", id), err)
+				return 0, errHelper(app, currConnection, fmt.Sprintf("no cathegory with id: '%d' in DB", id), err)
 			} else {
-				return errHelper(app, currConnection, fmt.Sprintf("getting a category with id: '%d' from DB faild", id), err)
+				return 0, errHelper(app, currConnection, fmt.Sprintf("getting a category with id: '%d' from DB faild", id), err)
 			}
 		}
 	}
 
-	id, err := app.ForumData.InsertPost(postData.Theme, postData.Content, nil, currConnection.session.User.ID, dateCreate, postData.CategoriesID)
+	id, err := app.ForumData.InsertPost(postData.Theme, postData.Content, postData.Images, currConnection.session.User.ID, dateCreate, postData.CategoriesID)
 	if err != nil {
-		return errHelper(app, currConnection, "insert a new post to DB failed", err)
+		return 0, errHelper(app, currConnection, "insert a new post to DB failed", err)
 	}
 
 	app.InfoLog.Printf("Post is added to DB. id: '%d' categories: '%v' ", id, postData.CategoriesID)
-	return nil
+	return id, nil
 }
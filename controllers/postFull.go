@@ -34,5 +34,6 @@ func replyFullPostAndComments(app *application.Application, currConnection *user
 	if err != nil {
 		return nil, err
 	}
+	currConnection.Client.ViewedPost.View(post.ID)
 	return post, nil
 }
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/controllers/ratelimit"
+	"forum/wsmodel"
+)
+
+/*
+takes the message from the budget of its type. If the budget is spent, sends an ERROR message with the time to retry
+and returns a warning, so the message is dropped. The connection is closed if the client keeps sending
+*/
+func (uc *usersConnection) checkRateLimit(app *application.Application, message wsmodel.WSMessage) error {
+	userID := 0
+	if uc.session.IsLoggedin() {
+		userID = uc.session.User.ID
+	}
+	key := ratelimit.Key(userID, uc.device.IP)
+
+	result := app.WSLimiter.Allow(key, message.Type, time.Now())
+	if result.Allowed {
+		return nil
+	}
+
+	if result.Violations >= application.RATE_LIMIT_MAX_VIOLATIONS {
+		reason := "too many requests"
+		disconnectClient(app, uc.Client, reason)
+		return fmt.Errorf("the client %s of '%s' is disconnected after %d rejected messages", uc.Client, key, result.Violations)
+	}
+
+	app.InfoLog.Printf("websocket:: the message '%s' of '%s' is rejected by the rate limit, retry after %s", message.Type, key, result.RetryAfter)
+	errMessage, err := wsmodel.CreateMessage(wsmodel.ERROR, "rateLimited", wsmodel.RateLimited{
+		RequestType: message.Type,
+		RetryAfter:  result.RetryAfter.Milliseconds(),
+		Message:     fmt.Sprintf("Too many requests, try again in %s", result.RetryAfter.Round(time.Second)),
+	})
+	if err != nil {
+		return errCreateMessage(app, uc, err)
+	}
+	wsMessage, err := json.Marshal(errMessage)
+	if err != nil {
+		return errMarshalJSON(app, uc, err)
+	}
+	uc.Client.WriteMessage(wsMessage)
+
+	return errors.Join(wsmodel.ErrWarning, fmt.Errorf("the rate limit of '%s' is exceeded", message.Type))
+}
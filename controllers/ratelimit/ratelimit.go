@@ -0,0 +1,140 @@
+// Package ratelimit implements token buckets, which limit the requests of the users to the forum
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// the full buckets and the old violations are deleted not more often than the period
+const cleanPeriod = time.Minute
+
+// Budget is the size of a bucket and the time to refill the empty bucket
+type Budget struct {
+	Burst  int           // the number of requests allowed at once
+	Period time.Duration // the time in which the whole burst is refilled
+}
+
+func (b Budget) rate() float64 {
+	return float64(b.Burst) / b.Period.Seconds()
+}
+
+// Result is the answer of the limiter to a request
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration // the time until the next request of the kind is allowed
+	Violations int           // the number of the rejected requests of the key during the violation window
+}
+
+type bucket struct {
+	budget  Budget
+	tokens  float64
+	updated time.Time
+}
+
+type violations struct {
+	count int
+	start time.Time
+}
+
+/*
+Limiter keeps a bucket for every key and kind of requests. The kinds which have no budgets share the default budget
+*/
+type Limiter struct {
+	mu              sync.Mutex
+	defaultBudget   Budget
+	budgets         map[string]Budget
+	violationWindow time.Duration
+	buckets         map[string]*bucket
+	violations      map[string]*violations
+	lastClean       time.Time
+}
+
+func New(defaultBudget Budget, budgets map[string]Budget, violationWindow time.Duration) *Limiter {
+	return &Limiter{
+		defaultBudget:   defaultBudget,
+		budgets:         budgets,
+		violationWindow: violationWindow,
+		buckets:         make(map[string]*bucket),
+		violations:      make(map[string]*violations),
+	}
+}
+
+// Key returns the key of a user, or of the IP address for an anonymous user
+func Key(userID int, ip string) string {
+	if userID > 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + ip
+}
+
+/*
+takes a token from the bucket of the key and the kind. If the bucket is empty, the request is rejected
+and counted as a violation of the key
+*/
+func (l *Limiter) Allow(key, kind string, now time.Time) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clean(now)
+
+	budget, ok := l.budgets[kind]
+	if !ok {
+		budget, kind = l.defaultBudget, ""
+	}
+
+	bucketKey := key + "|" + kind
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		b = &bucket{budget: budget, tokens: float64(budget.Burst), updated: now}
+		l.buckets[bucketKey] = b
+	}
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true}
+	}
+
+	v, ok := l.violations[key]
+	if !ok || now.Sub(v.start) > l.violationWindow {
+		v = &violations{start: now}
+		l.violations[key] = v
+	}
+	v.count++
+
+	retryAfter := time.Duration((1 - b.tokens) / budget.rate() * float64(time.Second))
+	return Result{RetryAfter: retryAfter, Violations: v.count}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.updated) {
+		b.tokens += now.Sub(b.updated).Seconds() * b.budget.rate()
+		b.updated = now
+	}
+	if b.tokens > float64(b.budget.Burst) {
+		b.tokens = float64(b.budget.Burst)
+	}
+}
+
+/*
+deletes the buckets, which are full by now, and the violations out of the window, so the maps don't grow
+with the keys seen once
+*/
+func (l *Limiter) clean(now time.Time) {
+	if now.Sub(l.lastClean) < cleanPeriod {
+		return
+	}
+	l.lastClean = now
+
+	for bucketKey, b := range l.buckets {
+		if now.Sub(b.updated) >= b.budget.Period {
+			delete(l.buckets, bucketKey)
+		}
+	}
+	for key, v := range l.violations {
+		if now.Sub(v.start) > l.violationWindow {
+			delete(l.violations, key)
+		}
+	}
+}
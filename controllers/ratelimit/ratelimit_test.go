@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(Budget{Burst: 5, Period: 5 * time.Second}, map[string]Budget{"post": {Burst: 2, Period: time.Minute}}, time.Minute)
+	user, anonymous := Key(1, "10.0.0.1"), Key(0, "10.0.0.1")
+
+	for i := 0; i < 2; i++ {
+		if result := limiter.Allow(user, "post", now); !result.Allowed {
+			t.Fatalf("the request %d within the burst is rejected", i+1)
+		}
+	}
+	result := limiter.Allow(user, "post", now)
+	if result.Allowed {
+		t.Fatal("the request over the burst is allowed")
+	}
+	if result.RetryAfter != 30*time.Second || result.Violations != 1 {
+		t.Errorf("got RetryAfter %s and %d violations, want 30s and 1", result.RetryAfter, result.Violations)
+	}
+
+	if result := limiter.Allow(user, "comment", now); !result.Allowed {
+		t.Error("a kind without a budget is limited by the budget of another kind")
+	}
+	if result := limiter.Allow(anonymous, "post", now); !result.Allowed {
+		t.Error("the anonymous user of the same IP is limited by the budget of the user")
+	}
+
+	if result := limiter.Allow(user, "post", now.Add(30*time.Second)); !result.Allowed {
+		t.Error("the request after the retry time is rejected")
+	}
+	result = limiter.Allow(user, "post", now.Add(31*time.Second))
+	if result.Allowed || result.Violations != 2 {
+		t.Errorf("got allowed %v with %d violations, want a rejection with 2 violations", result.Allowed, result.Violations)
+	}
+	result = limiter.Allow(user, "post", now.Add(2*time.Minute))
+	if !result.Allowed {
+		t.Error("the request after the refill is rejected")
+	}
+	limiter.Allow(user, "post", now.Add(2*time.Minute))
+	result = limiter.Allow(user, "post", now.Add(2*time.Minute))
+	if result.Allowed || result.Violations != 1 {
+		t.Errorf("got allowed %v with %d violations, want a rejection with 1 violation after the window", result.Allowed, result.Violations)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if key := Key(7, "10.0.0.1"); key != "user:7" {
+		t.Errorf("got the key '%s' for a user, want 'user:7'", key)
+	}
+	if key := Key(0, "10.0.0.1"); key != "ip:10.0.0.1" {
+		t.Errorf("got the key '%s' for an anonymous user, want 'ip:10.0.0.1'", key)
+	}
+}
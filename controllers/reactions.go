@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"forum/application"
+	"forum/controllers/chat"
 	"forum/controllers/liker"
 	"forum/model"
 	"forum/wsmodel"
@@ -23,12 +24,13 @@ type Response struct {
 	AmountOfReactions int `json:"reactionAmount"` // Likes or dislikes, corresponding on request
 }
 
+/*
+sets the reaction of the current user to a post or a comment.
+The new numbers of likes/dislikes are sent to the other clients viewing the post, except the clients of the users
+who blocked the author of the post or the comment, so the counters are updated live.
+Only the requester gets the own reaction in the reply
+*/
 func replyReaction(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any ,error) {
-	err := checkLoggedStatus(app, currConnection, message)
-	if err != nil {
-		return nil, err
-	}
-
 	reactionData, err := parse.PayloadToReaction(message.Payload)
 	if err != nil {
 		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload in reaction request: %s", message.Payload), err)
@@ -44,11 +46,19 @@ func replyReaction(app *application.Application, currConnection *usersConnection
 	case model.POST:
 		react = liker.NewLikePost(currConnection.session.User, reactionData)
 	case model.COMMENT:
-		react = liker.NewLikePost(currConnection.session.User, reactionData)
+		react = liker.NewLikeComment(currConnection.session.User, reactionData)
 	default:
 		return nil, errHelper(app, currConnection, fmt.Sprintf("unexpected message type in reaction request %s", reactionData.MessageType), errors.New("unexpected message type"))
 	}
 
+	postID, authorID, err := app.ForumData.GetMessagePostAndAuthor(reactionData.MessageType, reactionData.MessageID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a %s with id '%d'", reactionData.MessageType, reactionData.MessageID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the %s from DB failed", reactionData.MessageType), err)
+	}
+
 	if err = liker.SetLike(app.ForumData, react, reactionData.Reaction); err != nil {
 		return nil, errHelper(app, currConnection, "DB error during reaction handling", err)
 	}
@@ -59,5 +69,17 @@ func replyReaction(app *application.Application, currConnection *usersConnection
 		return nil, errHelper(app, currConnection, "get new reactions failed", err)
 	}
 
+	isNotBlocker, err := getIsNotBlocker(app, currConnection, authorID)
+	if err != nil {
+		return nil, err
+	}
+	isRecipient := func(client *chat.Client) bool {
+		return client.ViewedPost.IsViewing(postID) && isNotBlocker(client)
+	}
+	err = sendMessageToLoggedClients(app, currConnection, wsmodel.ReactionsUpdated, newReactions.Counters(), isRecipient)
+	if err != nil {
+		return nil, err
+	}
+
 	return newReactions, nil
 }
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"forum/application"
-	"forum/controllers/chat"
 	"forum/wsmodel"
 
 	"github.com/gorilla/websocket"
@@ -38,8 +37,17 @@ var NewLine = []byte("\n")
 // reads from this goroutine.
 func (uc *usersConnection) ReadPump(app *application.Application, w http.ResponseWriter) {
 	defer func() {
+		close(uc.closed)
+		uc.mu.Lock()
+		defer uc.mu.Unlock()
+
+		err := stopAllTyping(app, uc)
+		if err != nil {
+			app.ErrLog.Printf("ReadPump: error stopping typing: %v", err)
+		}
+
 		// (online changes)app.Hub.UnRegisterFromHub(uc.Client)
-		err := uc.deleteClientAndSendUserOffline(app, uc.Client)
+		err = uc.deleteClientAndSendUserOffline(app, uc.Client)
 		if err != nil {
 			app.ErrLog.Printf("ReadPump: error client delete: %v", err)
 		}
@@ -65,43 +73,60 @@ func (uc *usersConnection) ReadPump(app *application.Application, w http.Respons
 			app.InfoLog.Printf("ReadPump is closing connection %p  of client  '%s' : %#v", uc.Client.Conn, uc.Client, err)
 			break
 		}
-		if message.IsAuthentification() {
-			// (online changes) oldUser := uc.Client.User
-			err = replierAuthenticators[message.Type](app, w, uc, message)
-			if err != nil {
-				if errors.Is(err, wsmodel.ErrWarning) {
-					continue
-				} else {
-					break
-				}
-			}
-			// (online changes)
-			// if uc.session.IsLoggedin() {
-			// 	err = sendOnlineUsers(app, uc)
-			// 	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
-			// 		break
-			// 	}
-			// } else {
-			// 	err = sendOfflineUserToUsers(app, uc, oldUser)
-			// 	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
-			// 		break
-			// 	}
-			// }
-
-		} else {
-			replier, ok := repliers[message.Type]
-			if !ok {
-				app.ErrLog.Printf("unknown type message received: %s", message.Type)
-				continue
-			}
+		uc.mu.Lock()
+		err = uc.dispatch(app, w, message)
+		uc.mu.Unlock()
+		if err != nil {
+			break
+		}
+	}
+}
 
-			err := replier(app, uc, message)
-			if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
-				break
-			}
+/*
+replies to the message. Returns an error only if the connection has to be closed
+*/
+func (uc *usersConnection) dispatch(app *application.Application, w http.ResponseWriter, message wsmodel.WSMessage) error {
+	err := uc.checkRateLimit(app, message)
+	if errors.Is(err, wsmodel.ErrWarning) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
+	uc.updateLastSeen(app)
+	if message.IsAuthentification() {
+		// (online changes) oldUser := uc.Client.User
+		err = replierAuthenticators[message.Type](app, w, uc, message)
+		if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+			return err
 		}
+		// (online changes)
+		// if uc.session.IsLoggedin() {
+		// 	err = sendOnlineUsers(app, uc)
+		// 	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		// 		break
+		// 	}
+		// } else {
+		// 	err = sendOfflineUserToUsers(app, uc, oldUser)
+		// 	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		// 		break
+		// 	}
+		// }
+		return nil
+	}
+
+	replier, ok := repliers[message.Type]
+	if !ok {
+		app.ErrLog.Printf("unknown type message received: %s", message.Type)
+		return nil
+	}
+
+	err = replier(app, uc, message)
+	if err != nil && !errors.Is(err, wsmodel.ErrWarning) {
+		return err
 	}
+	return nil
 }
 
 // writePump pumps messages from the hub to the websocket connection.
@@ -109,64 +134,67 @@ func (uc *usersConnection) ReadPump(app *application.Application, w http.Respons
 // A goroutine running writePump is started for each connection. The
 // application ensures that there is at most one writer to a connection by
 // executing all writes from this goroutine.
+// The client of the connection is replaced at login and logout, but the new client keeps the connection and the channel,
+// so they are taken once under the lock and the pump doesn't read uc.Client anymore
 func (uc *usersConnection) WritePump(app *application.Application) {
+	uc.mu.Lock()
+	conn, chann := uc.Client.Conn, uc.Client.ReceivedMessages
+	uc.mu.Unlock()
+
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
-		err := uc.Client.Conn.Close()
+		err := conn.Close()
 		if err != nil {
 			app.ErrLog.Printf("WritePump: error closing connection: %v", err)
 		}
-		app.InfoLog.Printf("WritePump closed connection %p", uc.Client.Conn)
+		app.InfoLog.Printf("WritePump closed connection %p", conn)
 	}()
 	for {
-		chann := uc.Client.ReceivedMessages
 		select {
 		case message, ok := <-chann:
 			if !ok {
-				// The hub closed the channel.
-				uc.Client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
-				app.InfoLog.Printf("WritePump is closing connection because the hub closed the channel %p ", chann)
-				app.InfoLog.Printf("WritePump is closing connection %p of client '%s' because the hub closed the channel %p ", uc.Client.Conn, uc.Client, uc.Client.ReceivedMessages)
+				// The ReadPump closed the channel.
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				app.InfoLog.Printf("WritePump is closing connection %p because the channel %p is closed", conn, chann)
 				return
 			}
 
-			// currentChannel := uc.Client.ReceivedMessages
-			uc.Client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
-			w, err := uc.Client.Conn.NextWriter(websocket.TextMessage)
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			w, err := conn.NextWriter(websocket.TextMessage)
 			if err != nil {
-				app.ErrLog.Printf("cannot create the NextWriter on the connenction %p : %v", uc.Client.Conn, err)
+				app.ErrLog.Printf("cannot create the NextWriter on the connenction %p : %v", conn, err)
 				return
 			}
-			writeMessage(app, w, message, uc.Client)
+			writeMessage(app, w, message, conn)
 
 			// Add queued chat messages to the current websocket message.
 			n := len(chann)
 			for i := 0; i < n; i++ {
 				message = <-chann
-				writeMessage(app, w, message, uc.Client)
+				writeMessage(app, w, message, conn)
 			}
 
 			if err := w.Close(); err != nil {
-				app.ErrLog.Printf("cannot close the writer on the connenction %p : %v", uc.Client.Conn, err)
+				app.ErrLog.Printf("cannot close the writer on the connenction %p : %v", conn, err)
 				return
 			}
 		case <-ticker.C:
-			uc.Client.Conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := uc.Client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				app.ErrLog.Printf("ping the connenction %s failed: %v", uc.Client.Conn.LocalAddr(), err)
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				app.ErrLog.Printf("ping the connenction %s failed: %v", conn.LocalAddr(), err)
 				return
 			}
 		}
 	}
 }
 
-func writeMessage(app *application.Application, w io.WriteCloser, message []byte, currentClient *chat.Client) error {
+func writeMessage(app *application.Application, w io.WriteCloser, message []byte, conn *websocket.Conn) error {
 	_, err := w.Write(message)
 	if err != nil {
 		return err
 	}
-	app.InfoLog.Printf("Websocket: send message: '%s' to client %s", shortMessage(message), currentClient)
+	app.InfoLog.Printf("Websocket: send message: '%s' to connection %p", shortMessage(message), conn)
 	_, err = w.Write(NewLine)
 	if err != nil {
 		return err
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+saves a report of the current user about a post, a comment or a chat message.
+Chat messages can be reported only by members of the chat. The new report is sent to the online moderators
+*/
+func replyReport(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	report, err := parse.PayloadToReport(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a report: '%s'", message.Payload), err)
+	}
+
+	errmessage := report.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	err = checkReportedItem(app, currConnection, report, message)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := app.ForumData.InsertReport(currConnection.session.User.ID, report.TargetType, report.TargetID, report.Reason, time.Now())
+	if errors.Is(err, model.ErrUnique) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the %s %d is reported already", report.TargetType, report.TargetID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "adding the report to DB failed", err)
+	}
+	app.InfoLog.Printf("%s %d is reported by %s", report.TargetType, report.TargetID, currConnection.session.User)
+
+	newReport, err := getReport(app, currConnection, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendToModerators(app, currConnection, wsmodel.ReportCreated, newReport)
+	if err != nil {
+		return nil, err
+	}
+	return newReport, nil
+}
+
+/*
+replies with a portion of the reports queue
+*/
+func replyReports(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	portion, err := parse.PayloadToReportsPortion(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for the portion of reports: '%s'", message.Payload), err)
+	}
+
+	errmessage := portion.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	statuses := []string{model.REPORT_OPEN, model.REPORT_CLAIMED}
+	if portion.Status != "" {
+		statuses = []string{portion.Status}
+	}
+
+	reports, err := app.ForumData.GetReports(statuses, portion.BeforeID, REPORTS_PORTION)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the reports from DB failed", err)
+	}
+	return reports, nil
+}
+
+/*
+assigns the open report to the current moderator, so other moderators don't handle it.
+The change is sent to the other online moderators
+*/
+func replyClaimReport(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	reportID, err := parse.PayloadToInt(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for claiming a report: '%s'", message.Payload), err)
+	}
+
+	err = app.ForumData.ClaimReport(reportID, currConnection.session.User.ID, time.Now())
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find an open report with id '%d'", reportID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "claiming the report in DB failed", err)
+	}
+	app.InfoLog.Printf("report %d is claimed by %s", reportID, currConnection.session.User)
+
+	return sendReportUpdated(app, currConnection, reportID)
+}
+
+func replyResolveReport(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	return closeReport(app, currConnection, message, model.REPORT_RESOLVED, model.MOD_RESOLVE_REPORT)
+}
+
+func replyDismissReport(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	return closeReport(app, currConnection, message, model.REPORT_DISMISSED, model.MOD_DISMISS_REPORT)
+}
+
+/*
+closes the report with the status, the action is added to the moderation log.
+The change is sent to the other online moderators
+*/
+func closeReport(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage, status, action string) (any, error) {
+	reportID, err := parse.PayloadToInt(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for closing a report: '%s'", message.Payload), err)
+	}
+
+	err = app.ForumData.CloseReport(reportID, currConnection.session.User.ID, status, time.Now())
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find an open report with id '%d' or the report is claimed by another moderator", reportID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "closing the report in DB failed", err)
+	}
+
+	err = logModeration(app, currConnection, action, model.REPORT, reportID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return sendReportUpdated(app, currConnection, reportID)
+}
+
+/*
+checks that the reported item exists and the current user can see it
+*/
+func checkReportedItem(app *application.Application, currConnection *usersConnection, report wsmodel.Report, message wsmodel.WSMessage) error {
+	notFound := func() error {
+		return badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find a %s with id '%d'", report.TargetType, report.TargetID))
+	}
+
+	if report.TargetType != model.CHAT_MESSAGE {
+		exists, err := app.ForumData.IsMessageExisting(report.TargetType, report.TargetID)
+		if err != nil {
+			return errHelper(app, currConnection, fmt.Sprintf("checking the %s %d in DB failed", report.TargetType, report.TargetID), err)
+		}
+		if !exists {
+			return notFound()
+		}
+		return nil
+	}
+
+	chatID, _, err := app.ForumData.GetChatMessageAuthor(report.TargetID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return notFound()
+	}
+	if err != nil {
+		return errHelper(app, currConnection, "get the chat message from DB failed", err)
+	}
+	isMember, err := app.ForumData.IsChatMember(chatID, currConnection.session.User.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "checking the chat member in DB failed", err)
+	}
+	if !isMember {
+		return notFound()
+	}
+	return nil
+}
+
+func getReport(app *application.Application, currConnection *usersConnection, reportID int) (*model.Report, error) {
+	report, err := app.ForumData.GetReportByID(reportID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the report %d from DB failed", reportID), err)
+	}
+	return report, nil
+}
+
+/*
+sends the changed report to the other online moderators, returns the report
+*/
+func sendReportUpdated(app *application.Application, currConnection *usersConnection, reportID int) (*model.Report, error) {
+	report, err := getReport(app, currConnection, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendToModerators(app, currConnection, wsmodel.ReportUpdated, report)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+/*
+sends the message to the other clients of the online users with the role ROLE_MODERATOR or higher
+*/
+func sendToModerators(app *application.Application, currConnection *usersConnection, messageType string, data any) error {
+	onlineUsers, err := app.ForumData.GetFilteredUsers(app.Hub.GetOnlineUsers())
+	if err != nil {
+		return errHelper(app, currConnection, "get the online users from DB failed", err)
+	}
+
+	moderators := make(map[int]bool)
+	for _, user := range onlineUsers {
+		if user.Role.IsAtLeast(model.ROLE_MODERATOR) {
+			moderators[user.ID] = true
+		}
+	}
+
+	isModerator := func(client *chat.Client) bool {
+		return client.User != nil && moderators[client.User.ID]
+	}
+	return sendMessageToLoggedClients(app, currConnection, messageType, data, isModerator)
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+replies with SEARCH_RESULTS_PORTION posts, comments and messages of the user's chats found by the full-text search
+*/
+func replySearch(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	search, err := parse.PayloadToSearch(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a search: '%s'", message.Payload), err)
+	}
+
+	errmessage := search.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	results, err := app.ForumData.Search(search.Query, currConnection.session.User.ID, search.After, SEARCH_RESULTS_PORTION)
+	if errors.Is(err, model.ErrNoSearch) {
+		return nil, badRequestHelper(app, currConnection, message, "search is not available")
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "searching in DB failed", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,108 @@
+// Package totp implements the time-based one-time passwords (RFC 6238) used as the second factor of the login
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	PERIOD       = 30 * time.Second // a code changes with the period
+	DIGITS       = 6
+	secretLength = 20 // the length of the secret in bytes, as the length of an SHA-1 hash
+	// the codes of the previous and the next periods are accepted too, because the clocks can differ
+	allowedSkew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random secret encoded in base32, as the authenticator apps expect it
+func NewSecret() (string, error) {
+	data := make([]byte, secretLength)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(data), nil
+}
+
+/*
+returns the otpauth URI, which the authenticator apps read from a QR code to add the account
+*/
+func ProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	params := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(DIGITS)},
+		"period":    {fmt.Sprint(int(PERIOD.Seconds()))},
+	}
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Step returns the number of the period the time belongs to
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(PERIOD.Seconds())
+}
+
+/*
+returns the code of the step, the secret is encoded in base32
+*/
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return code(key, step, DIGITS), nil
+}
+
+// code is the HOTP value (RFC 4226) of the counter
+func code(key []byte, counter int64, digits int) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulo)
+}
+
+/*
+checks the code at the time 'now'. The code must belong to a step after 'lastStep',
+so a used code cannot be used again. Returns the step of the code, which has to be kept as the last used step
+*/
+func Validate(secret, userCode string, now time.Time, lastStep int64) (int64, bool) {
+	userCode = strings.TrimSpace(userCode)
+	if len(userCode) != DIGITS {
+		return 0, false
+	}
+	current := Step(now)
+	for step := current - allowedSkew; step <= current+allowedSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(userCode)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// the test vectors of RFC 6238 for SHA-1
+func TestCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unixTime int64
+		want     string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, test := range tests {
+		got := code(key, Step(time.Unix(test.unixTime, 0)), 8)
+		if got != test.want {
+			t.Errorf("time %d: got %s, want %s", test.unixTime, got, test.want)
+		}
+	}
+
+	got, err := Code(encoding.EncodeToString(key), Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "287082" {
+		t.Errorf("got the 6 digits code %s, want 287082", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	step := Step(now)
+	previousCode, _ := Code(secret, step-1)
+	oldCode, _ := Code(secret, step-3)
+
+	gotStep, ok := Validate(secret, previousCode, now, 0)
+	if !ok || gotStep != step-1 {
+		t.Errorf("the code of the previous period: got step %d, %t, want %d", gotStep, ok, step-1)
+	}
+	if _, ok = Validate(secret, previousCode, now, step-1); ok {
+		t.Errorf("a used code is accepted again")
+	}
+	if _, ok = Validate(secret, oldCode, now, 0); ok {
+		t.Errorf("an old code is accepted")
+	}
+	if _, ok = Validate(secret, "12345", now, 0); ok {
+		t.Errorf("a short code is accepted")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Forum", "john@example.com", "SECRET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Forum:john@example.com" {
+		t.Errorf("wrong URI %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != "SECRET" || query.Get("issuer") != "Forum" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("wrong parameters of the URI %s", uri)
+	}
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"forum/application"
+	"forum/controllers/totp"
+	"forum/model"
+	"forum/session"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+const (
+	TOTP_ISSUER           = "Forum"         // the name of the account in the authenticator apps
+	SECOND_FACTOR_TTL     = 5 * time.Minute // the code has to be sent during the time after the password
+	RECOVERY_CODES_NUMBER = 10
+	recoveryCodeLength    = 10 // the length of a recovery code in random bytes
+)
+
+// pendingSecondFactor is a login with the right password, which waits for the second factor
+type pendingSecondFactor struct {
+	user     *model.User
+	remember bool
+	expiry   time.Time
+}
+
+// twoFactorSetup is the secret of a new second factor, the URI is shown as a QR code
+type twoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+/*
+keeps the login of the user in the connection until the user sends the code in a loginSecondFactorRequest
+*/
+func requestSecondFactor(app *application.Application, currConnection *usersConnection, user *model.User, remember bool) error {
+	currConnection.secondFactor = &pendingSecondFactor{
+		user:     user,
+		remember: remember,
+		expiry:   time.Now().Add(SECOND_FACTOR_TTL),
+	}
+	app.InfoLog.Printf("User '%s' entered the password, the second factor is requested", user.Name)
+	return sendSuccessMessage(app, currConnection, wsmodel.SecondFactorRequired, user.Name)
+}
+
+/*
+finishes the login, which waits for the second factor, if the payload has a valid TOTP code or an unused recovery code.
+The wrong codes are counted as failed logins to the account
+*/
+func replyLoginSecondFactor(app *application.Application, w http.ResponseWriter, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	sessionStatus, err := currConnection.session.Tidy(app)
+	if err != nil {
+		return errHelper(app, currConnection, "invalid session status", err)
+	}
+	if sessionStatus == session.Loggedin {
+		return errHelper(app, currConnection, "login is forbidden", errors.New("a user has already logged in"))
+	}
+
+	pending := currConnection.secondFactor
+	if pending == nil || time.Now().After(pending.expiry) {
+		currConnection.secondFactor = nil
+		return badRequestHelper(app, currConnection, message, "Log in with the password again")
+	}
+
+	code, err := parse.PayloadToString(message.Payload)
+	if err != nil {
+		return errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a second factor code: '%s'", message.Payload), err)
+	}
+
+	err = checkLoginAllowedToAccount(app, currConnection, pending.user, message)
+	if err != nil {
+		return err
+	}
+
+	twoFactor, err := app.ForumData.GetTwoFactor(pending.user.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "getting the second factor of the user from DB failed", err)
+	}
+	valid, err := checkSecondFactorCode(app, currConnection, pending.user.ID, twoFactor, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		err = registerLoginFailure(app, currConnection, pending.user.ID)
+		if err != nil {
+			return err
+		}
+		return badRequestHelper(app, currConnection, message, "Wrong code")
+	}
+
+	currConnection.secondFactor = nil
+	return completeLogin(app, w, currConnection, message, pending.user, pending.remember)
+}
+
+/*
+creates a new secret of the current user and replies with it and the provisioning URI.
+The second factor is not used, until the user enables it with a code
+*/
+func replySetupTwoFactor(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, errHelper(app, currConnection, "creating the TOTP secret failed", err)
+	}
+
+	user := currConnection.session.User
+	err = app.ForumData.SetTwoFactorSecret(user.ID, secret, time.Now())
+	if errors.Is(err, model.ErrUnique) {
+		return nil, badRequestHelper(app, currConnection, message, "two-factor authentication is enabled already")
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "adding the TOTP secret to DB failed", err)
+	}
+
+	return twoFactorSetup{Secret: secret, URI: totp.ProvisioningURI(TOTP_ISSUER, user.Name, secret)}, nil
+}
+
+/*
+enables the second factor of the current user, if the code from the payload matches the secret,
+and replies with the new recovery codes. The codes are shown once, only their hashes are kept
+*/
+func replyEnableTwoFactor(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	code, err := parse.PayloadToString(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a second factor code: '%s'", message.Payload), err)
+	}
+
+	userID := currConnection.session.User.ID
+	twoFactor, err := app.ForumData.GetTwoFactor(userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, "two-factor authentication is not set up")
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the second factor of the user from DB failed", err)
+	}
+	if twoFactor.Enabled {
+		return nil, badRequestHelper(app, currConnection, message, "two-factor authentication is enabled already")
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), twoFactor.LastStep)
+	if !ok {
+		return nil, badRequestHelper(app, currConnection, message, "Wrong code")
+	}
+
+	recoveryCodes, codeHashes, err := createRecoveryCodes()
+	if err != nil {
+		return nil, errHelper(app, currConnection, "creating the recovery codes failed", err)
+	}
+	err = app.ForumData.EnableTwoFactor(userID, step, codeHashes)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "enabling the second factor in DB failed", err)
+	}
+	app.InfoLog.Printf("the user %s enabled two-factor authentication", currConnection.session.User)
+
+	return recoveryCodes, nil
+}
+
+/*
+disables the second factor of the current user, the payload has to have a valid TOTP code or an unused recovery code.
+Wrong codes count as failed logins to the account, so the codes cannot be guessed with a hijacked session
+*/
+func replyDisableTwoFactor(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	code, err := parse.PayloadToString(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a second factor code: '%s'", message.Payload), err)
+	}
+
+	userID := currConnection.session.User.ID
+	twoFactor, err := app.ForumData.GetTwoFactor(userID)
+	if errors.Is(err, model.ErrNoRecord) || (err == nil && !twoFactor.Enabled) {
+		return nil, badRequestHelper(app, currConnection, message, "two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the second factor of the user from DB failed", err)
+	}
+
+	err = checkLoginAllowedToAccount(app, currConnection, currConnection.session.User, message)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := checkSecondFactorCode(app, currConnection, userID, twoFactor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		err = registerLoginFailure(app, currConnection, userID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, badRequestHelper(app, currConnection, message, "Wrong code")
+	}
+
+	err = app.ForumData.DisableTwoFactor(userID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "disabling the second factor in DB failed", err)
+	}
+	app.InfoLog.Printf("the user %s disabled two-factor authentication", currConnection.session.User)
+
+	return userID, nil
+}
+
+func isTwoFactorEnabled(app *application.Application, userID int) (bool, error) {
+	twoFactor, err := app.ForumData.GetTwoFactor(userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+/*
+checks the TOTP code or the recovery code of the user, a valid code is used up
+*/
+func checkSecondFactorCode(app *application.Application, currConnection *usersConnection, userID int, twoFactor *model.TwoFactor, code string) (bool, error) {
+	now := time.Now()
+	step, ok := totp.Validate(twoFactor.Secret, code, now, twoFactor.LastStep)
+	if ok {
+		err := app.ForumData.UseTwoFactorStep(userID, step)
+		if errors.Is(err, model.ErrNoRecord) {
+			return false, nil // the code is used by a concurrent login
+		}
+		if err != nil {
+			return false, errHelper(app, currConnection, "using the TOTP code in DB failed", err)
+		}
+		return true, nil
+	}
+
+	err := app.ForumData.UseRecoveryCode(userID, hashSecretToken(normalizeRecoveryCode(code)), now)
+	if errors.Is(err, model.ErrNoRecord) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errHelper(app, currConnection, "using the recovery code in DB failed", err)
+	}
+	app.InfoLog.Printf("the user %d used a recovery code", userID)
+	return true, nil
+}
+
+/*
+returns the new recovery codes grouped by 4 characters, e.g. "ABCD-EFGH-IJKL-MNOP", and their hashes
+*/
+func createRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RECOVERY_CODES_NUMBER)
+	hashes := make([]string, RECOVERY_CODES_NUMBER)
+	data := make([]byte, recoveryCodeLength)
+	for i := range codes {
+		_, err := rand.Read(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data)
+
+		var groups []string
+		for len(code) > 4 {
+			groups = append(groups, code[:4])
+			code = code[4:]
+		}
+		codes[i] = strings.Join(append(groups, code), "-")
+		hashes[i] = hashSecretToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode lets the users type a recovery code in any case and without the dashes
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	"forum/application"
+	"forum/controllers/chat"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+relays the typing of the current user to the other members of the opened chat.
+The relaying is throttled by TYPING_THROTTLE. If the client doesn't confirm the typing during TYPING_TIMEOUT,
+the typing is stopped and 'typingStopped' is relayed. The timeout runs in its own goroutine,
+so it uses the connection under the connection's lock and only while the connection is open
+*/
+func processTypingStarted(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	openedChat, err := getTypingChat(app, currConnection, message)
+	if err != nil {
+		return err
+	}
+
+	user := currConnection.Client.User
+	onExpire := func() {
+		currConnection.mu.Lock()
+		defer currConnection.mu.Unlock()
+		if currConnection.isClosed() {
+			return
+		}
+		err := relayTyping(app, currConnection, openedChat, user, wsmodel.TypingStopped)
+		if err != nil {
+			app.ErrLog.Printf("relaying the end of typing of %s in the chat %d failed: %v", user, openedChat.ChatID, err)
+		}
+	}
+
+	if !currConnection.Client.Typing.Start(openedChat.ChatID, TYPING_THROTTLE, TYPING_TIMEOUT, onExpire) {
+		return nil
+	}
+	return relayTyping(app, currConnection, openedChat, user, wsmodel.TypingStarted)
+}
+
+func processTypingStopped(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	openedChat, err := getTypingChat(app, currConnection, message)
+	if err != nil {
+		return err
+	}
+
+	return stopTyping(app, currConnection, openedChat.ChatID)
+}
+
+/*
+returns the opened chat given in the typing indicator.
+Typing indicators don't have replies, so wrong indicators are only logged
+*/
+func getTypingChat(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (chat.OpenedChat, error) {
+	typing, err := parse.PayloadToTyping(message.Payload)
+	if err != nil {
+		return chat.OpenedChat{}, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a typing indicator: '%s'", message.Payload), err)
+	}
+
+	errmessage := typing.Validate()
+	if errmessage != "" {
+		return chat.OpenedChat{}, ignoreMessage(app, message, errmessage)
+	}
+
+	openedChat, ok := currConnection.Client.OpenedChats.Get(typing.ChatID)
+	if !ok {
+		return chat.OpenedChat{}, ignoreMessage(app, message, fmt.Sprintf("the chat %d is not opened", typing.ChatID))
+	}
+	return openedChat, nil
+}
+
+/*
+stops the typing of the current user in the chat and relays 'typingStopped', if the user was typing.
+Used when the user stops typing, sends a message or closes the chat
+*/
+func stopTyping(app *application.Application, currConnection *usersConnection, chatID int) error {
+	if !currConnection.Client.Typing.Stop(chatID) {
+		return nil
+	}
+
+	openedChat, ok := currConnection.Client.OpenedChats.Get(chatID)
+	if !ok {
+		return nil
+	}
+	return relayTyping(app, currConnection, openedChat, currConnection.Client.User, wsmodel.TypingStopped)
+}
+
+/*
+stops the typing of the current user in all the chats, used when the client is closed or replaced
+*/
+func stopAllTyping(app *application.Application, currConnection *usersConnection) error {
+	var errs error
+	for _, chatID := range currConnection.Client.Typing.StopAll() {
+		openedChat, ok := currConnection.Client.OpenedChats.Get(chatID)
+		if !ok {
+			continue
+		}
+		err := relayTyping(app, currConnection, openedChat, currConnection.Client.User, wsmodel.TypingStopped)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+/*
+sends the typing indicator of the 'user' to all the devices of the other members of the chat
+*/
+func relayTyping(app *application.Application, currConnection *usersConnection, openedChat chat.OpenedChat, user *model.User, messageType string) error {
+	membersID := []int{}
+	if openedChat.Type == model.GROUP_CHAT {
+		members, err := app.ForumData.GetChatMembers(openedChat.ChatID)
+		if err != nil {
+			return errHelper(app, currConnection, "get the chat members from DB failed", err)
+		}
+		for _, member := range members {
+			membersID = append(membersID, member.ID)
+		}
+	} else {
+		membersID = append(membersID, openedChat.User.ID)
+	}
+
+	typing := wsmodel.Typing{ChatID: openedChat.ChatID, User: user}
+	var errs error
+	for _, memberID := range membersID {
+		if memberID == user.ID {
+			continue
+		}
+		err := sendMessageToUser(app, currConnection, memberID, messageType, typing)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"forum/application"
+	"forum/controllers/images"
+	"forum/errorhandle"
+	"forum/wsmodel"
+)
+
+const IMAGES_URL_PREFIX = "/images/"
+
+type uploadedImage struct {
+	ID        string `json:"id"` // used to attach the image to posts, comments and chat messages
+	URL       string `json:"url"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+/*
+UploadImages saves the images sent in the multipart form field F_IMAGES and replies with their ids.
+The type of the images is checked by their content, the sizes are limited by MaxFileUploadSize and MaxUploadSize
+*/
+func UploadImages(app *application.Application) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+		err := r.ParseMultipartForm(MaxFileUploadSize)
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				uploadError(app, w, http.StatusRequestEntityTooLarge, fmt.Sprintf("the upload is larger than %d bytes", MaxUploadSize))
+				return
+			}
+			uploadError(app, w, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %v", err))
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		files := r.MultipartForm.File[F_IMAGES]
+		if len(files) == 0 {
+			uploadError(app, w, http.StatusBadRequest, "no images in the upload")
+			return
+		}
+		if len(files) > wsmodel.MAX_ATTACHED_IMAGES {
+			uploadError(app, w, http.StatusBadRequest, fmt.Sprintf("more than %d images in the upload", wsmodel.MAX_ATTACHED_IMAGES))
+			return
+		}
+
+		uploaded := make([]uploadedImage, 0, len(files))
+		for _, fileHeader := range files {
+			if fileHeader.Size > MaxFileUploadSize {
+				uploadError(app, w, http.StatusRequestEntityTooLarge, fmt.Sprintf("the file '%s' is larger than %d bytes", fileHeader.Filename, MaxFileUploadSize))
+				return
+			}
+
+			file, err := fileHeader.Open()
+			if err != nil {
+				errorhandle.ServerError(app, w, r, fmt.Sprintf("opening the uploaded file '%s' failed", fileHeader.Filename), err)
+				return
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				errorhandle.ServerError(app, w, r, fmt.Sprintf("reading the uploaded file '%s' failed", fileHeader.Filename), err)
+				return
+			}
+
+			id, err := images.Save(USER_IMAGES_DIR, data)
+			if errors.Is(err, images.ErrUnsupportedType) {
+				uploadError(app, w, http.StatusUnsupportedMediaType, fmt.Sprintf("the file '%s' is not a JPEG, PNG or GIF image", fileHeader.Filename))
+				return
+			}
+			if errors.Is(err, images.ErrTooLarge) {
+				uploadError(app, w, http.StatusRequestEntityTooLarge, fmt.Sprintf("the image '%s' is larger than %dx%d pixels", fileHeader.Filename, images.MAX_SIDE, images.MAX_SIDE))
+				return
+			}
+			if err != nil {
+				errorhandle.ServerError(app, w, r, fmt.Sprintf("saving the uploaded file '%s' failed", fileHeader.Filename), err)
+				return
+			}
+
+			uploaded = append(uploaded, uploadedImage{
+				ID:        id,
+				URL:       IMAGES_URL_PREFIX + id,
+				Thumbnail: IMAGES_URL_PREFIX + images.ThumbnailID(id),
+			})
+		}
+
+		app.InfoLog.Printf("%d images are uploaded", len(uploaded))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "images": uploaded})
+	}
+}
+
+/*
+ServeImages serves the uploaded images and their thumbnails, the directory itself is not listed
+*/
+func ServeImages(app *application.Application) http.Handler {
+	fileServer := http.StripPrefix(IMAGES_URL_PREFIX, http.FileServer(http.Dir(USER_IMAGES_DIR)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			errorhandle.NotFound(app, w, r)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+func uploadError(app *application.Application, w http.ResponseWriter, status int, errMessage string) {
+	app.InfoLog.Printf("upload of images failed: %s", errMessage)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"status": "failure", "error": errMessage})
+}
+
+/*
+checks that the images attached to a message are uploaded
+*/
+func checkAttachedImages(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage, imagesID []string) error {
+	for _, id := range imagesID {
+		if !images.Exists(USER_IMAGES_DIR, id) {
+			return badRequestHelper(app, currConnection, message, fmt.Sprintf("cannot find an uploaded image with id '%s'", id))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+/*
+replies with the profile of the user with the id given in the payload.
+The personal data is shown to the owner of the profile only,
+the activity of a user blocked by the current user is hidden
+*/
+func replyGetProfile(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	userID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || userID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a profile: '%s'", message.Payload), err)
+	}
+
+	user, err := getExistingUser(app, currConnection, userID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return getProfile(app, currConnection, user)
+}
+
+/*
+changes the personal data, the email and the password of the current user and replies with the changed profile.
+The password is changed only if the current password matches, the other sessions of the user are deleted then
+and the other devices of the user are disconnected
+*/
+func replyUpdateProfile(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	profileUpdate, err := parse.PayloadToProfileUpdate(message.Payload)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for a profile update: '%s'", message.Payload), err)
+	}
+
+	errmessage := profileUpdate.Validate()
+	if errmessage != "" {
+		return nil, badRequestHelper(app, currConnection, message, errmessage)
+	}
+
+	dateBirth, err := time.Parse(time.DateOnly, profileUpdate.DateBirth) // date must by in the format  "2006-01-02"
+	if err != nil {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("failed to parse the date of birth: %v", err))
+	}
+
+	userID := currConnection.session.User.ID
+	user, err := getExistingUser(app, currConnection, userID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	var newPassword []byte
+	if profileUpdate.NewPassword != "" {
+		if err = bcrypt.CompareHashAndPassword(user.Password, []byte(profileUpdate.CurrentPassword)); err != nil {
+			return nil, badRequestHelper(app, currConnection, message, "Wrong current password")
+		}
+		newPassword, err = bcrypt.GenerateFromPassword([]byte(profileUpdate.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, errHelper(app, currConnection, "failed to generate crypto password", err)
+		}
+	}
+
+	if profileUpdate.Email != user.Email {
+		err = app.ForumData.ChangeUsersEmail(userID, profileUpdate.Email)
+		if errors.Is(err, model.ErrUniqueUserEmail) {
+			return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("Account with email '%s' already exists", profileUpdate.Email))
+		}
+		if err != nil {
+			return nil, errHelper(app, currConnection, "changing the email of the user in DB failed", err)
+		}
+		app.InfoLog.Printf("user %s changed the email, it has to be verified", currConnection.session.User)
+	}
+
+	err = app.ForumData.ChangeUsersPersonalData(userID, profileUpdate.FirstName, profileUpdate.LastName, profileUpdate.Gender, dateBirth)
+	if err != nil {
+		return nil, errHelper(app, currConnection, "changing the personal data of the user in DB failed", err)
+	}
+	app.InfoLog.Printf("user %s changed the profile", currConnection.session.User)
+
+	if newPassword != nil {
+		err = changePassword(app, currConnection, newPassword)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	user, err = getExistingUser(app, currConnection, userID, message)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Verified {
+		err = sendVerificationEmail(app, user)
+		if err != nil && !errors.Is(err, errTooManyEmails) {
+			return nil, errHelper(app, currConnection, "sending the verification email failed", err)
+		}
+	}
+	currConnection.session.User.Email = user.Email
+	currConnection.session.User.DateBirth = user.DateBirth
+	currConnection.session.User.Gender = user.Gender
+	currConnection.session.User.FirstName = user.FirstName
+	currConnection.session.User.LastName = user.LastName
+
+	return getProfile(app, currConnection, user)
+}
+
+/*
+saves the new password hash of the current user and logs the user out on the other devices
+*/
+func changePassword(app *application.Application, currConnection *usersConnection, password []byte) error {
+	user := currConnection.session.User
+	err := app.ForumData.ChangeUsersPassword(user.ID, string(password))
+	if err != nil {
+		return errHelper(app, currConnection, "changing the password of the user in DB failed", err)
+	}
+
+	err = app.ForumData.DeleteUserSessionsExcept(user.ID, user.Uuid)
+	if err != nil {
+		return errHelper(app, currConnection, "deleting the other sessions of the user failed", err)
+	}
+	disconnectOtherSessions(app, user.ID, user.Uuid, "the password is changed")
+
+	app.InfoLog.Printf("user %s changed the password", user)
+	return nil
+}
+
+/*
+closes the connections of the user's clients, which are logged in with sessions other than 'sessionUUID'
+*/
+func disconnectOtherSessions(app *application.Application, userID int, sessionUUID string, reason string) {
+	for _, client := range app.Hub.GetUsersClients(userID) {
+		if client.SessionUUID != sessionUUID {
+			disconnectClient(app, client, reason)
+		}
+	}
+}
+
+/*
+collects the profile of the user for the current user
+*/
+func getProfile(app *application.Application, currConnection *usersConnection, user *model.User) (*model.Profile, error) {
+	profile := &model.Profile{
+		User: &model.User{
+			ID:         user.ID,
+			Name:       user.Name,
+			DateCreate: user.DateCreate,
+			Role:       user.Role,
+			Sanctions:  model.Sanctions{Banned: user.Banned},
+			Verified:   user.Verified,
+		},
+		RecentActivity: []*model.Activity{},
+	}
+	if user.ID == currConnection.session.User.ID {
+		profile.User.Email = user.Email
+		profile.User.DateBirth = user.DateBirth
+		profile.User.Gender = user.Gender
+		profile.User.FirstName = user.FirstName
+		profile.User.LastName = user.LastName
+		profile.User.Sanctions = user.Sanctions
+	}
+
+	var err error
+	profile.PostsCount, profile.CommentsCount, err = app.ForumData.GetUserMessagesCount(user.ID)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the number of messages of the user %d from DB failed", user.ID), err)
+	}
+
+	blockedUsersID, err := getBlockedUsersID(app, currConnection)
+	if err != nil {
+		return nil, err
+	}
+	if blockedUsersID[user.ID] {
+		return profile, nil
+	}
+
+	profile.RecentActivity, err = app.ForumData.GetUserRecentActivity(user.ID, PROFILE_ACTIVITY_LIMIT)
+	if err != nil {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("get the recent activity of the user %d from DB failed", user.ID), err)
+	}
+	return profile, nil
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"forum/application"
+	"forum/model"
+	"forum/session"
+	"forum/wsmodel"
+	"forum/wsmodel/parse"
+)
+
+/*
+replies with the active sessions of the current user, the session of the current connection is marked
+*/
+func replyListSessions(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	sessions, err := app.ForumData.GetUserSessions(currConnection.session.User.ID, time.Now())
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the sessions of the user from DB failed", err)
+	}
+	for _, userSession := range sessions {
+		userSession.Device = describeAgent(userSession.Agent)
+		userSession.Current = userSession.Uuid == currConnection.session.User.Uuid
+	}
+	return sessions, nil
+}
+
+/*
+deletes the session of the current user with the id given in the payload
+and closes the connections of the clients, which use the session.
+The current session is ended with a logout
+*/
+func replyRevokeSession(app *application.Application, currConnection *usersConnection, message wsmodel.WSMessage) (any, error) {
+	sessionID, err := parse.PayloadToInt(message.Payload)
+	if err != nil || sessionID <= 0 {
+		return nil, errHelper(app, currConnection, fmt.Sprintf("Invalid payload for revoking a session: '%s'", message.Payload), err)
+	}
+
+	userID := currConnection.session.User.ID
+	sessions, err := app.ForumData.GetUserSessions(userID, time.Now())
+	if err != nil {
+		return nil, errHelper(app, currConnection, "getting the sessions of the user from DB failed", err)
+	}
+	for _, userSession := range sessions {
+		if userSession.ID == sessionID && userSession.Uuid == currConnection.session.User.Uuid {
+			return nil, badRequestHelper(app, currConnection, message, "the current session cannot be revoked, log out instead")
+		}
+	}
+
+	uuid, err := app.ForumData.DeleteUserSessionByID(userID, sessionID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return nil, badRequestHelper(app, currConnection, message, fmt.Sprintf("the session %d doesn't exist", sessionID))
+	}
+	if err != nil {
+		return nil, errHelper(app, currConnection, "deleting the session from DB failed", err)
+	}
+	app.InfoLog.Printf("the session %d of the user %s is revoked", sessionID, currConnection.session.User)
+
+	for _, client := range app.Hub.GetUsersClients(userID) {
+		if client.SessionUUID == uuid {
+			disconnectClient(app, client, "the session is revoked")
+		}
+	}
+	return sessionID, nil
+}
+
+/*
+deletes all the sessions of the current user, closes the connections of the user's other clients
+and logs the current connection out
+*/
+func replyLogoutEverywhere(app *application.Application, w http.ResponseWriter, currConnection *usersConnection, message wsmodel.WSMessage) error {
+	sessionStatus, err := currConnection.session.Tidy(app)
+	if err != nil {
+		return errHelper(app, currConnection, "invalid session status", err)
+	}
+	if sessionStatus == session.Notloggedin {
+		return errHelper(app, currConnection, "logout is forbidden", errors.New("no logged user"))
+	}
+
+	user := currConnection.session.User
+	err = app.ForumData.DeleteUserSessions(user.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "deleting the sessions of the user failed", err)
+	}
+
+	for _, client := range app.Hub.GetUsersClients(user.ID) {
+		if client != currConnection.Client {
+			disconnectClient(app, client, "logged out everywhere")
+		}
+	}
+
+	newSess := session.GetNotloggedinSession()
+
+	err = sendReply(app, currConnection, message, newSess)
+	if err != nil {
+		return err
+	}
+
+	app.InfoLog.Printf("User '%s' logged out everywhere", user.Name)
+
+	currConnection.session = newSess
+	return currConnection.renewClientForUser(app, newSess)
+}
+
+/*
+updates the last seen time of the connection's session, if the time was updated more than LAST_SEEN_PERIOD ago,
+so the idle timeout of the session slides while the user is active
+*/
+func (uc *usersConnection) updateLastSeen(app *application.Application) {
+	if !uc.session.IsLoggedin() || time.Since(uc.lastSeenUpdate) < LAST_SEEN_PERIOD {
+		return
+	}
+	uc.lastSeenUpdate = time.Now()
+	err := uc.session.Touch(app, uc.lastSeenUpdate)
+	if err != nil {
+		app.ErrLog.Printf("updating the session of the user %s failed: %v", uc.session.User, err)
+	}
+}
+
+/*
+checks the session of the connection every app.Session.CheckPeriod until the connection is closed.
+The session can expire or be deleted while the connection is open
+*/
+func (uc *usersConnection) SessionCheckPump(app *application.Application) {
+	ticker := time.NewTicker(app.Session.CheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-uc.closed:
+			return
+		case <-ticker.C:
+			uc.mu.Lock()
+			err := uc.checkSession(app)
+			uc.mu.Unlock()
+			if err != nil {
+				app.ErrLog.Printf("checking the session of the client %s failed: %v", uc.Client, err)
+			}
+		}
+	}
+}
+
+/*
+sends the sessionExpired message and makes the client not logged in, if the session of the connection lapsed
+*/
+func (uc *usersConnection) checkSession(app *application.Application) error {
+	if uc.session == nil || uc.session.User == nil {
+		return nil
+	}
+	user := uc.session.User
+	valid, err := uc.session.Check(app)
+	if err != nil || valid {
+		return err
+	}
+	app.InfoLog.Printf("the session of the user '%s' expired", user.Name)
+
+	newSess := session.GetNotloggedinSession()
+	err = sendSuccessMessage(app, uc, wsmodel.SessionExpired, newSess)
+	if err != nil {
+		return err
+	}
+
+	uc.session = newSess
+	return uc.renewClientForUser(app, newSess)
+}
+
+// the known browsers and operating systems, the more specific names go first
+var (
+	knownBrowsers = []string{"Edg", "OPR", "Firefox", "Chrome", "Safari"}
+	browserNames  = map[string]string{"Edg": "Edge", "OPR": "Opera"}
+	knownSystems  = []string{"Android", "iPhone", "iPad", "Windows", "Mac OS", "Linux"}
+	systemNames   = map[string]string{"Mac OS": "macOS"}
+)
+
+/*
+returns the browser and the operating system described by the user agent, e.g. "Firefox on Linux"
+*/
+func describeAgent(agent string) string {
+	browser := findName(agent, knownBrowsers, browserNames)
+	system := findName(agent, knownSystems, systemNames)
+	switch {
+	case browser == "" && system == "":
+		return "Unknown device"
+	case system == "":
+		return browser
+	case browser == "":
+		return system
+	}
+	return browser + " on " + system
+}
+
+func findName(agent string, known []string, names map[string]string) string {
+	for _, name := range known {
+		if strings.Contains(agent, name) {
+			if fullName, ok := names[name]; ok {
+				return fullName
+			}
+			return name
+		}
+	}
+	return ""
+}
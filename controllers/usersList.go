@@ -12,10 +12,15 @@ import (
 )
 
 /*
-sends list of online users
+sends list of online users, the users blocked by the current user are not in the list
 */
 func sendOnlineUsersToCurrentUser(app *application.Application, currConnection *usersConnection, onlineUsers chat.MapID) error {
-	users, err := app.ForumData.GetFilteredUsersOrderedByMessagesToGivenUser(onlineUsers, currConnection.session.User.ID)
+	blockedUsersID, err := getBlockedUsersID(app, currConnection)
+	if err != nil {
+		return err
+	}
+
+	users, err := app.ForumData.GetFilteredUsersOrderedByMessagesToGivenUser(model.Except(onlineUsers, blockedUsersID), currConnection.session.User.ID)
 	if err != nil {
 		return errHelper(app, currConnection, "get the users from DB failed", err)
 	}
@@ -60,11 +65,24 @@ func sendOnlineUsers(app *application.Application, currConnection *usersConnecti
 		return errHelper(app, currConnection, fmt.Sprintf("send list of online users to the user %s faild", currConnection.session.User), err)
 	}
 
-	// send the new online user to the other users
+	// the other users know about the current user already, if the user is online on another device
+	if len(onlineUsers[currConnection.session.User.ID]) > 1 {
+		return nil
+	}
+
+	// send the new online user to the other users, except the users who blocked the current user
+	blockersID, err := app.ForumData.GetBlockersID(currConnection.session.User.ID)
+	if err != nil {
+		return errHelper(app, currConnection, "get the users who blocked the user from DB failed", err)
+	}
+
 	var errs error
 
-	for userID, client := range onlineUsers {
-		if userID != currConnection.session.User.ID {
+	for userID, clients := range onlineUsers {
+		if userID == currConnection.session.User.ID || blockersID[userID] {
+			continue
+		}
+		for _, client := range clients {
 			err = sendNewOnlineUserToClient(app, currConnection, client)
 			if err != nil {
 				errs = errors.Join(errs, errHelper(app, currConnection, fmt.Sprintf("send new online user to the client %s faild\n", client), err))
@@ -85,10 +103,12 @@ func sendOfflineUserToUsers(app *application.Application, currConnection *usersC
 	onlineUsers := app.Hub.GetOnlineUsers()
 
 	var errs error
-	for _, client := range onlineUsers {
-		err := sendMessageToOtherClient(app, currConnection, client, wsmodel.OfflineUser, userOff)
-		if err != nil {
-			errs = errors.Join(errs, errMarshalJSON(app, currConnection, err))
+	for _, clients := range onlineUsers {
+		for _, client := range clients {
+			err := sendMessageToOtherClient(app, currConnection, client, wsmodel.OfflineUser, userOff)
+			if err != nil {
+				errs = errors.Join(errs, errMarshalJSON(app, currConnection, err))
+			}
 		}
 	}
 	if errs != nil {
@@ -112,3 +132,39 @@ func sendMessageToOtherClient(app *application.Application, currConnection *user
 	recipient.WriteMessage(wsMessage)
 	return nil
 }
+
+/*
+sends the message to all the clients of the hub, including the current client
+*/
+func sendMessageToAllClients(app *application.Application, currConnection *usersConnection, messageType string, data any) error {
+	message, err := wsmodel.CreateMessage(messageType, "success", data)
+	if err != nil {
+		return errCreateMessage(app, currConnection, err)
+	}
+
+	wsMessage, err := json.Marshal(message)
+	if err != nil {
+		return errMarshalJSON(app, currConnection, err)
+	}
+
+	app.Hub.SendMessageToAllClients(wsMessage)
+	return nil
+}
+
+/*
+sends the message to all the clients (devices) of the user, except the client of the current connection.
+Nothing is sent if the user is offline
+*/
+func sendMessageToUser(app *application.Application, currConnection *usersConnection, userID int, messageType string, data any) error {
+	var errs error
+	for _, client := range app.Hub.GetUsersClients(userID) {
+		if client == currConnection.Client {
+			continue
+		}
+		err := sendMessageToOtherClient(app, currConnection, client, messageType, data)
+		if err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
@@ -4,11 +4,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"forum/application"
+	"forum/controllers/mailer"
+	"forum/controllers/oauth"
 	"forum/model/sqlpkg"
 	"forum/route"
 )
@@ -62,6 +67,12 @@ func main() {
 	}
 	defer app.ForumData.DB.Close()
 
+	app.BaseURL = fmt.Sprintf("http://localhost:%s", port)
+	configureMailer(app)
+	configureOAuth(app)
+	configureSessions(app)
+	configureTrustedProxies(app)
+
 	// Starting the web server
 	server := &http.Server{
 		Addr:     fmt.Sprintf("localhost:%s", port),
@@ -101,6 +112,115 @@ func parseArgs() (port string, pristineDB bool, testDB bool, err error) {
 	return
 }
 
+// Sends the emails through the SMTP server given in the environment variables SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. If SMTP_HOST is not set, the emails are written to the info log
+func configureMailer(app *application.Application) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		app.InfoLog.Println("SMTP_HOST is not set, the emails are written to the info log")
+		return
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	app.Mailer = &mailer.SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+	app.InfoLog.Printf("the emails are sent through the SMTP server %s:%s", host, port)
+}
+
+// Enables the login with GitHub and Google if their client ids and secrets are given in the environment variables
+// GITHUB_CLIENT_ID, GITHUB_CLIENT_SECRET, GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET.
+// The endpoints of a provider can be changed with <PROVIDER>_AUTH_URL, <PROVIDER>_TOKEN_URL, <PROVIDER>_USERINFO_URL
+// and GITHUB_EMAILS_URL, e.g. to use a local fake provider
+func configureOAuth(app *application.Application) {
+	newProviders := map[string]func(clientID, clientSecret, redirectURL string) *oauth.Provider{
+		oauth.GITHUB: oauth.NewGitHub,
+		oauth.GOOGLE: oauth.NewGoogle,
+	}
+	for name, newProvider := range newProviders {
+		prefix := strings.ToUpper(name) + "_"
+		clientID := os.Getenv(prefix + "CLIENT_ID")
+		if clientID == "" {
+			continue
+		}
+		provider := newProvider(clientID, os.Getenv(prefix+"CLIENT_SECRET"), fmt.Sprintf("%s/login/%s/callback", app.BaseURL, name))
+		setFromEnv(&provider.AuthURL, prefix+"AUTH_URL")
+		setFromEnv(&provider.TokenURL, prefix+"TOKEN_URL")
+		setFromEnv(&provider.UserInfoURL, prefix+"USERINFO_URL")
+		setFromEnv(&provider.EmailsURL, prefix+"EMAILS_URL")
+
+		app.OAuthProviders[name] = provider
+		app.InfoLog.Printf("the login with %s is enabled", name)
+	}
+}
+
+// Sets the lifetimes of the sessions from the environment variables SESSION_ABSOLUTE_TIMEOUT, SESSION_REMEMBER_TIMEOUT,
+// SESSION_IDLE_TIMEOUT and SESSION_CHECK_PERIOD given as Go durations, e.g. "12h" or "30m"
+func configureSessions(app *application.Application) {
+	durations := map[string]*time.Duration{
+		"SESSION_ABSOLUTE_TIMEOUT": &app.Session.AbsoluteTimeout,
+		"SESSION_REMEMBER_TIMEOUT": &app.Session.RememberTimeout,
+		"SESSION_IDLE_TIMEOUT":     &app.Session.IdleTimeout,
+		"SESSION_CHECK_PERIOD":     &app.Session.CheckPeriod,
+	}
+	for key, duration := range durations {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			app.ErrLog.Fatalf("%s must be a positive duration, e.g. 12h: '%s'", key, value)
+		}
+		*duration = parsed
+	}
+	app.InfoLog.Printf("the sessions expire after %s, %s with \"remember me\", or after %s of inactivity",
+		app.Session.AbsoluteTimeout, app.Session.RememberTimeout, app.Session.IdleTimeout)
+}
+
+// Sets the reverse proxies, whose X-Forwarded-For headers are trusted, from the environment variable TRUSTED_PROXIES
+// given as comma separated IP addresses or CIDR ranges, e.g. "127.0.0.1,10.0.0.0/8". Without it the clients'
+// IP addresses are the addresses of the direct peers, which are the proxy itself behind a reverse proxy
+func configureTrustedProxies(app *application.Application) {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return
+	}
+	for _, proxy := range strings.Split(value, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				app.ErrLog.Fatalf("TRUSTED_PROXIES must be IP addresses or CIDR ranges: '%s'", proxy)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			app.TrustedProxies = append(app.TrustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			app.ErrLog.Fatalf("TRUSTED_PROXIES must be IP addresses or CIDR ranges: '%s'", proxy)
+		}
+		app.TrustedProxies = append(app.TrustedProxies, network)
+	}
+	app.InfoLog.Printf("the clients' IP addresses are taken from X-Forwarded-For of the proxies %s", value)
+}
+
+func setFromEnv(value *string, key string) {
+	if envValue := os.Getenv(key); envValue != "" {
+		*value = envValue
+	}
+}
+
 func createAndFillTestDB(app *application.Application) {
 	err := app.CreateDB(DB_Name)
 	if err != nil {
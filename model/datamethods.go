@@ -75,7 +75,7 @@ func (c *Chat) String() string {
 	for _, m := range c.Messages {
 		messages += fmt.Sprintf("%s\n--------\n", m)
 	}
-	return fmt.Sprintf("chat id: %d | name: %s | type: %d  \n   Messages:\n%s\n\n", c.ID, c.Name, c.Type, messages)
+	return fmt.Sprintf("chat id: %d | name: %s | type: %d | title: %s | members: %v \n   Messages:\n%s\n\n", c.ID, c.Name, c.Type, c.Title, c.Members, messages)
 }
 
 func (m ChatMessage) String() string {
@@ -11,9 +11,82 @@ type Time time.Time
 const (
 	POST    = "post"
 	COMMENT = "comment"
+	USER    = "user" // used as a target type in the moderation log only
+
+	CHAT_MESSAGE = "chatMessage" // used as a target type of reports only
+	REPORT       = "report"      // used as a target type in the moderation log only
 )
 const N_LIKES = 2
 
+// types of chats, kept in the chats.type column
+const (
+	PRIVATE_CHAT = iota
+	GROUP_CHAT
+)
+
+// kinds of the items found by the full-text search
+const (
+	SEARCH_POST = iota + 1
+	SEARCH_COMMENT
+	SEARCH_CHAT_MESSAGE
+)
+
+// Role defines the rights of a user, kept in the users.role column
+type Role string
+
+const (
+	ROLE_USER      Role = "user"
+	ROLE_MODERATOR Role = "moderator" // can delete any post or comment, lock posts and ban users
+	ROLE_ADMIN     Role = "admin"     // has the rights of a moderator and can change roles of users
+)
+
+var roleLevels = map[Role]int{
+	ROLE_USER:      0,
+	ROLE_MODERATOR: 1,
+	ROLE_ADMIN:     2,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// IsHigherThan reports whether the role has more rights than the role 'other'
+func (r Role) IsHigherThan(other Role) bool {
+	return r.IsAtLeast(other) && roleLevels[r] > roleLevels[other]
+}
+
+// IsAtLeast reports whether the role has all the rights of the role 'other'
+func (r Role) IsAtLeast(other Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[other]
+}
+
+// actions of moderators, kept in the moderation log
+const (
+	MOD_DELETE_POST    = "deletePost"
+	MOD_DELETE_COMMENT = "deleteComment"
+	MOD_LOCK_POST      = "lockPost"
+	MOD_UNLOCK_POST    = "unlockPost"
+	MOD_BAN_USER       = "banUser"
+	MOD_UNBAN_USER     = "unbanUser"
+	MOD_SET_ROLE       = "setRole"
+	MOD_SUSPEND_USER   = "suspendUser"
+	MOD_UNSUSPEND_USER = "unsuspendUser"
+	MOD_MUTE_USER      = "muteUser"
+	MOD_UNMUTE_USER    = "unmuteUser"
+	MOD_RESOLVE_REPORT = "resolveReport"
+	MOD_DISMISS_REPORT = "dismissReport"
+)
+
+// statuses of reports, kept in the reports.status column
+const (
+	REPORT_OPEN      = "open"
+	REPORT_CLAIMED   = "claimed"   // a moderator is handling the report
+	REPORT_RESOLVED  = "resolved"  // the reported content is moderated
+	REPORT_DISMISSED = "dismissed" // the report is groundless
+)
+
 const (
 	DISLIKE UserReactions = iota
 	LIKE
@@ -25,6 +98,7 @@ var (
 	ErrUnique          = errors.New("unique constraint failed")
 	ErrUniqueUserName  = errors.New("user with the given name already exists")
 	ErrUniqueUserEmail = errors.New("user with the given email already exists")
+	ErrNoSearch        = errors.New("full-text search is not available")
 )
 
 type UserReactions int
@@ -42,6 +116,49 @@ type User struct {
 	Uuid            string    `json:"uuid,omitempty"`
 	ExpirySession   time.Time `json:"expirySession,omitempty"`
 	LastMessageDate string    `json:"lastMessageDate"`
+	Role            Role      `json:"role,omitempty"`
+	Verified        bool      `json:"verified"` // the user confirmed the email, unverified users cannot post or chat
+	SessionLastSeen time.Time `json:"-"`        // the time the session of the user was used last
+	RememberSession bool      `json:"-"`        // the session was created with "remember me", it has no idle timeout
+	Sanctions
+}
+
+// Sanctions are the restrictions imposed on a user by moderators
+type Sanctions struct {
+	Banned         bool       `json:"banned,omitempty"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"` // the user cannot log in until the time
+	MutedUntil     *time.Time `json:"mutedUntil,omitempty"`     // the user can read but cannot post, comment or chat until the time
+}
+
+// IsSuspended reports whether the user is banned or suspended at the moment 'now'
+func (s Sanctions) IsSuspended(now time.Time) bool {
+	return s.Banned || (s.SuspendedUntil != nil && now.Before(*s.SuspendedUntil))
+}
+
+// IsMuted reports whether the user is muted at the moment 'now'
+func (s Sanctions) IsMuted(now time.Time) bool {
+	return s.MutedUntil != nil && now.Before(*s.MutedUntil)
+}
+
+// TwoFactor is the TOTP second factor of a user, it is not enabled until the user confirms it with a code
+type TwoFactor struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64 // the period of the last used code, the codes of the period and the earlier ones cannot be used
+}
+
+// UserSession is a logged in device of a user, the uuid is the secret token of the session and is not sent to the clients
+type UserSession struct {
+	ID            int       `json:"id"`
+	Uuid          string    `json:"-"`
+	Agent         string    `json:"agent"`
+	Device        string    `json:"device"` // the browser and the OS described by the agent
+	IP            string    `json:"ip"`
+	DateCreate    time.Time `json:"dateCreate"`
+	LastSeen      time.Time `json:"lastSeen"`
+	ExpirySession time.Time `json:"expirySession"`
+	Remember      bool      `json:"remember"` // the session was created with "remember me"
+	Current       bool      `json:"current"`  // the session of the device, which requested the list
 }
 
 type message struct {
@@ -49,7 +166,7 @@ type message struct {
 	Content      string        `json:"content"`
 	DateCreate   time.Time     `json:"dateCreate,omitempty"`
 	Likes        []int         `json:"likes,omitempty"` // index 0 keeps number of dislikes, index 1 keeps number of likes
-	Images       []string      `json:"-"`
+	Images       []string      `json:"images,omitempty"`
 	UserReaction UserReactions `json:"userReaction,omitempty"` //-1 => no reaction
 }
 
@@ -60,6 +177,57 @@ type Post struct {
 	Categories       []*Category `json:"categories"`
 	Comments         []*Comment  `json:"comments,omitempty"`
 	CommentsQuantity int         `json:"commentsQuantity,omitempty"`
+	Locked           bool        `json:"locked,omitempty"` // no new comments can be added to a locked post
+}
+
+// ModerationLogEntry is an action of a moderator, see the MOD_... constants
+type ModerationLogEntry struct {
+	ID         int       `json:"id"`
+	Moderator  *User     `json:"moderator,omitempty"` // nil if the moderator's account is deleted
+	Action     string    `json:"action"`
+	TargetType string    `json:"targetType"` // POST, COMMENT or USER
+	TargetID   int       `json:"targetID"`
+	Reason     string    `json:"reason,omitempty"`
+	Date       time.Time `json:"date"`
+}
+
+// Report is a complaint of a user about a post, a comment or a chat message
+type Report struct {
+	ID         int       `json:"id"`
+	Reporter   *User     `json:"reporter,omitempty"` // nil if the reporter's account is deleted
+	TargetType string    `json:"targetType"`         // POST, COMMENT or CHAT_MESSAGE
+	TargetID   int       `json:"targetID"`
+	Reason     string    `json:"reason"`
+	Status     string    `json:"status"`              // one of the REPORT_... constants
+	Moderator  *User     `json:"moderator,omitempty"` // the moderator who claimed or closed the report
+	DateCreate time.Time `json:"dateCreate"`
+	DateUpdate time.Time `json:"dateUpdate,omitempty"`
+}
+
+// Profile is a user with the statistics and the latest posts and comments of the user
+type Profile struct {
+	User           *User       `json:"user"`
+	PostsCount     int         `json:"postsCount"`
+	CommentsCount  int         `json:"commentsCount"`
+	RecentActivity []*Activity `json:"recentActivity"`
+}
+
+// Activity is a post or a comment of a user, shown in the profile of the user
+type Activity struct {
+	Type       string    `json:"type"` // POST or COMMENT
+	ID         int       `json:"id"`
+	PostID     int       `json:"postID"` // the id of the post itself for posts
+	Theme      string    `json:"theme"`  // the theme of the post
+	Content    string    `json:"content"`
+	DateCreate time.Time `json:"dateCreate"`
+}
+
+// MessageVersion is a previous version of an edited post or comment, kept in the edit history
+type MessageVersion struct {
+	Theme    string    `json:"theme,omitempty"` // for posts only
+	Content  string    `json:"content"`
+	Images   []string  `json:"-"`
+	DateEdit time.Time `json:"dateEdit"`
 }
 
 type Category struct {
@@ -74,18 +242,40 @@ type Comment struct {
 }
 
 type Chat struct {
-	ID       int           `json:"id"`
-	Name     string        `json:"name"`
-	Type     int           `json:"type"`
-	Messages []ChatMessage `json:"message"`
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Type      int           `json:"type"`
+	Title     string        `json:"title,omitempty"`     // for group chats only
+	CreatorID int           `json:"creatorID,omitempty"` // for group chats only
+	Members   []*User       `json:"members,omitempty"`
+	Messages  []ChatMessage `json:"message"`
 }
 
 type ChatMessage struct {
 	ID         int       `json:"id"`
+	ChatID     int       `json:"chatID,omitempty"`
 	Author     *User     `json:"author,omitempty"`
 	Content    string    `json:"content"`
 	DateCreate time.Time `json:"dateCreate,omitempty"`
-	Images     []string  `json:"-"`
+	Images     []string  `json:"images,omitempty"`
+	// the last dates when the message was delivered to/read by the other members of the chat
+	DateDelivered string `json:"dateDelivered,omitempty"`
+	DateRead      string `json:"dateRead,omitempty"`
+	// a deleted message is kept as a tombstone with empty content and the date of deleting
+	DateEdit   string `json:"dateEdit,omitempty"`
+	DateDelete string `json:"dateDelete,omitempty"`
+}
+
+/*
+keeps the number of chat messages which the user has not read yet in the chat.
+UserID is the other member of a private chat
+*/
+type UnreadChat struct {
+	ChatID   int    `json:"chatID"`
+	ChatName string `json:"chatName"`
+	Type     int    `json:"type"`
+	UserID   int    `json:"userID,omitempty"`
+	Unread   int    `json:"unread"`
 }
 
 type Filter struct {
@@ -95,9 +285,57 @@ type Filter struct {
 	DisLikedByUserID int   `json:"disLikedByUserID"`
 }
 
+/*
+the position of a search result in the ranked list of results.
+The next portion of results starts after the cursor, the zero cursor means the beginning of the list
+*/
+type SearchCursor struct {
+	Rank  float64 `json:"rank"`
+	RowID int     `json:"rowID"`
+}
+
+/*
+an item found by the full-text search. Title and Snippet are HTML-escaped and contain the found words
+highlighted with SEARCH_HIGHLIGHT_START and SEARCH_HIGHLIGHT_END marks
+*/
+type SearchResult struct {
+	Kind       int          `json:"kind"`
+	ID         int          `json:"id"`
+	PostID     int          `json:"postID,omitempty"` // for comments only
+	ChatID     int          `json:"chatID,omitempty"` // for chat messages only
+	Title      string       `json:"title,omitempty"`  // for posts only
+	Snippet    string       `json:"snippet"`
+	Author     *User        `json:"author"`
+	DateCreate time.Time    `json:"dateCreate"`
+	Cursor     SearchCursor `json:"cursor"`
+}
+
 /*
 checkID must return true if a user with the given ID has to be added to the result of selection from DB
 */
 type IdChecker interface {
 	CheckID(id int) bool
 }
+
+// IDSet is a set of ids, it accepts the ids from the set
+type IDSet map[int]bool
+
+func (s IDSet) CheckID(id int) bool {
+	return s[id]
+}
+
+type idCheckerFunc func(id int) bool
+
+func (f idCheckerFunc) CheckID(id int) bool {
+	return f(id)
+}
+
+/*
+Except returns an IdChecker which accepts the ids accepted by 'checker' and not accepted by 'excluded',
+e.g. online users except the users blocked by the current user
+*/
+func Except(checker, excluded IdChecker) IdChecker {
+	return idCheckerFunc(func(id int) bool {
+		return checker.CheckID(id) && !excluded.CheckID(id)
+	})
+}
@@ -0,0 +1,89 @@
+package sqlpkg
+
+import (
+	"time"
+
+	"forum/model"
+)
+
+/*
+adds the block of the user 'blockedID' by the user 'blockerID'. Returns ErrUnique if the user is blocked already
+*/
+func (f *ForumModel) BlockUser(blockerID, blockedID int, dateCreate time.Time) error {
+	q := `INSERT INTO user_blocks (blockerID, blockedID, dateCreate) VALUES (?,?,?)
+		  ON CONFLICT (blockerID, blockedID) DO NOTHING`
+	res, err := f.DB.Exec(q, blockerID, blockedID, dateCreate)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUnique
+	}
+	return nil
+}
+
+/*
+removes the block of the user 'blockedID' by the user 'blockerID'. Returns ErrNoRecord if the user is not blocked
+*/
+func (f *ForumModel) UnblockUser(blockerID, blockedID int) error {
+	q := `DELETE FROM user_blocks WHERE blockerID=? AND blockedID=?`
+	res, err := f.DB.Exec(q, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+returns the ids of the users blocked by the user
+*/
+func (f *ForumModel) GetBlockedUsersID(blockerID int) (model.IDSet, error) {
+	return f.getBlocksID(`SELECT blockedID FROM user_blocks WHERE blockerID=?`, blockerID)
+}
+
+/*
+returns the ids of the users who blocked the user
+*/
+func (f *ForumModel) GetBlockersID(blockedID int) (model.IDSet, error) {
+	return f.getBlocksID(`SELECT blockerID FROM user_blocks WHERE blockedID=?`, blockedID)
+}
+
+/*
+returns true if either of the users blocked the other one
+*/
+func (f *ForumModel) IsBlockedBetween(userID1, userID2 int) (bool, error) {
+	var blocked bool
+	q := `SELECT EXISTS (SELECT 1 FROM user_blocks
+			WHERE (blockerID=? AND blockedID=?) OR (blockerID=? AND blockedID=?))`
+	err := f.DB.QueryRow(q, userID1, userID2, userID2, userID1).Scan(&blocked)
+	return blocked, err
+}
+
+func (f *ForumModel) getBlocksID(query string, userID int) (model.IDSet, error) {
+	rows, err := f.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(model.IDSet)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,107 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestBlockUser(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const blockerID, blockedID = 2, 3
+	now := time.Now()
+
+	postID, err := f.InsertPost("theme of the blocked user", "content of the blocked user", nil, blockedID, now, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeletePost(postID)
+	ownPostID, err := f.InsertPost("theme of the blocker", "content of the blocker", nil, blockerID, now, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeletePost(ownPostID)
+	_, err = f.InsertComment(ownPostID, "comment of the blocked user", nil, blockedID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.BlockUser(blockerID, blockedID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnblockUser(blockerID, blockedID)
+	err = f.BlockUser(blockerID, blockedID, now)
+	if !errors.Is(err, model.ErrUnique) {
+		t.Errorf("blocking a blocked user: got error %v, want %v", err, model.ErrUnique)
+	}
+
+	blocked, err := f.IsBlockedBetween(blockedID, blockerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blocked {
+		t.Errorf("the block between the users %d and %d is not found", blockerID, blockedID)
+	}
+	blockedIDs, err := f.GetBlockedUsersID(blockerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockersID, err := f.GetBlockersID(blockedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blockedIDs.CheckID(blockedID) || !blockersID.CheckID(blockerID) {
+		t.Errorf("wrong ids of the blocks: blocked %v, blockers %v", blockedIDs, blockersID)
+	}
+	online := model.IDSet{blockerID: true, blockedID: true}
+	if model.Except(online, blockedIDs).CheckID(blockedID) || !model.Except(online, blockedIDs).CheckID(blockerID) {
+		t.Errorf("the blocked user is not excluded from the online users")
+	}
+
+	_, err = f.GetPostByID(postID, blockerID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("getting a post of the blocked user: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	ownPost, err := f.GetPostByID(ownPostID, blockerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ownPost.Comments) != 0 {
+		t.Errorf("the comments of the blocked user are not hidden: %v", ownPost.Comments)
+	}
+	posts, err := f.GetPosts(0, 10, &model.Filter{}, blockerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, post := range posts {
+		if post.ID == postID {
+			t.Errorf("the post %d of the blocked user is not hidden", postID)
+		}
+		if post.ID == ownPostID && post.CommentsQuantity != 0 {
+			t.Errorf("the comments of the blocked user are counted: %d", post.CommentsQuantity)
+		}
+	}
+
+	_, err = f.GetPostByID(postID, blockedID)
+	if err != nil {
+		t.Errorf("the blocked user cannot get the own post: %v", err)
+	}
+
+	err = f.UnblockUser(blockerID, blockedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.UnblockUser(blockerID, blockedID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("unblocking a not blocked user: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
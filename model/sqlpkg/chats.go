@@ -18,7 +18,7 @@ func (f *ForumModel) CreatePrivatChat(userID1, userID2 int) (*model.Chat, error)
 	} else {
 		name = fmt.Sprintf("%d-%d", userID2, userID1)
 	}
-	res, err := f.DB.Exec(q, name, 0)
+	res, err := f.DB.Exec(q, name, model.PRIVATE_CHAT)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ func (f *ForumModel) CreatePrivatChat(userID1, userID2 int) (*model.Chat, error)
 		return nil, err
 	}
 
-	return &model.Chat{ID: chatID, Name: name, Type: 0, Messages: []model.ChatMessage{}}, nil
+	return &model.Chat{ID: chatID, Name: name, Type: model.PRIVATE_CHAT, Messages: []model.ChatMessage{}}, nil
 }
 
 func (f *ForumModel) AddUserToChat(chatID, userID int) error {
@@ -78,27 +78,71 @@ func (f *ForumModel) InsertChatMessage(chatID int, authorID int, content string,
 		return 0, err
 	}
 
+	err = f.updateSearchIndex(f.DB, model.SEARCH_CHAT_MESSAGE, int(id))
+	if err != nil {
+		return 0, err
+	}
+
 	return int(id), nil
 }
 
+/*
+deletes the chat message from DB. Used to roll back inserting of a message,
+the messages deleted by users are kept as tombstones, see MarkChatMessageDeleted
+*/
 func (f *ForumModel) DeleteChatMessage(id int) error {
 	q := `DELETE FROM chat_messages WHERE id=?`
 	_, err := f.DB.Exec(q, id)
 	if err != nil {
 		return err
 	}
-	return nil
+	return f.deleteFromSearchIndex(f.DB, model.SEARCH_CHAT_MESSAGE, id)
+}
+
+/*
+changes the content of the chat message and keeps the date of editing.
+Returns ErrNoRecord if there is no such message or the message is deleted
+*/
+func (f *ForumModel) EditChatMessage(id int, content string, dateEdit time.Time) error {
+	q := `UPDATE chat_messages SET content=?, dateEdit=? WHERE id=? AND dateDelete IS NULL`
+	res, err := f.DB.Exec(q, content, dateEdit, id)
+	if err != nil {
+		return err
+	}
+	err = f.checkUnique(res)
+	if err != nil {
+		return err
+	}
+	return f.updateSearchIndex(f.DB, model.SEARCH_CHAT_MESSAGE, id)
+}
+
+/*
+replaces the chat message with a tombstone: the content is removed, the date of deleting is kept.
+Returns ErrNoRecord if there is no such message or the message is deleted already
+*/
+func (f *ForumModel) MarkChatMessageDeleted(id int, dateDelete time.Time) error {
+	q := `UPDATE chat_messages SET content='', images=NULL, dateDelete=? WHERE id=? AND dateDelete IS NULL`
+	res, err := f.DB.Exec(q, dateDelete, id)
+	if err != nil {
+		return err
+	}
+	err = f.checkUnique(res)
+	if err != nil {
+		return err
+	}
+	return f.deleteFromSearchIndex(f.DB, model.SEARCH_CHAT_MESSAGE, id)
 }
 
 func (f *ForumModel) GetLastMessageDateFromUserToRecipient(userId int, recipientID int) (string, error) {
 	q := `SELECT max(ms.dateCreate) FROM chat_messages ms 
 	WHERE ms.chat_membersID IN (SELECT  mb.id as mbID FROM chat_members mb
-		WHERE mb.userID=? AND mb.chatID IN (SELECT chatID FROM chat_members WHERE userID=?)) 
+		WHERE mb.userID=? AND mb.chatID IN (SELECT chatID FROM chat_members WHERE userID=?)
+		AND mb.chatID IN (SELECT id FROM chats WHERE type=?)) 
 	GROUP BY ms.chat_membersID `
 
 	//var messageDateCreate sql.NullTime
 	var messageDateCreate sql.NullString
-	row := f.DB.QueryRow(q, recipientID, userId)
+	row := f.DB.QueryRow(q, recipientID, userId, model.PRIVATE_CHAT)
 	err := row.Scan(&messageDateCreate)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -120,9 +164,9 @@ func (f *ForumModel) GetPrivateChat(usrID1, usrID2 int) (int, string, error) {
 			
 			WHERE ch.id IN (SELECT chatID FROM chat_members cmb WHERE cmb.userID=?) 
 			  AND ch.id IN (SELECT chatID FROM chat_members cmb WHERE cmb.userID=?) 
-			  AND ch.type=0 ` // for privat chats type=0
+			  AND ch.type=? `
 
-	rows, err := f.DB.Query(q, usrID1, usrID2)
+	rows, err := f.DB.Query(q, usrID1, usrID2, model.PRIVATE_CHAT)
 	if err != nil {
 		return 0, "", err
 	}
@@ -163,7 +207,10 @@ If beforeId and/or messageNumbers is less or equal to 0 it will ignore the condi
 func (f *ForumModel) GetPrivateChatMessagesByChatId(id int, beforeId int, messageNumbers int) (*model.Chat, error) {
 	condition, arguments := createConditionSelectMessagesByChatId(id, beforeId)
 	query := createQuerySelectMessages(
-		" chat_messages.id, chat_messages.content, chat_messages.images, chat_messages.dateCreate, chat_members.userID, users.name ",
+		` chat_messages.id, chat_messages.content, chat_messages.images, chat_messages.dateCreate, chat_members.userID, users.name, 
+		(SELECT max(r.dateDelivered) FROM chat_messages_receipts r WHERE r.messageID=chat_messages.id), 
+		(SELECT max(r.dateRead) FROM chat_messages_receipts r WHERE r.messageID=chat_messages.id), 
+		chat_messages.dateEdit, chat_messages.dateDelete `,
 		condition,
 	)
 	chat, err := f.execQuerySelectMessages(query, arguments, messageNumbers, scanRowToChatMessageAndUser)
@@ -252,12 +299,17 @@ Used in the execQuerySelectMessages function.
 func scanRowToChatMessageAndUser(rows *sql.Rows, chat *model.Chat) error {
 	author := model.User{}
 	message := model.ChatMessage{}
-	var images sql.NullString
+	var images, dateDelivered, dateRead, dateEdit, dateDelete sql.NullString
 
 	// parse the row with fields:
-	// SELECT  chat_messages.id, chat_messages.content, chat_messages.images, chat_messages.dateCreate, chat_members.userID, users.name
-	err := rows.Scan(&message.ID, &message.Content, &images, &message.DateCreate, &author.ID, &author.Name)
+	// SELECT  chat_messages.id, chat_messages.content, chat_messages.images, chat_messages.dateCreate, chat_members.userID, users.name,
+	// max(r.dateDelivered), max(r.dateRead), chat_messages.dateEdit, chat_messages.dateDelete
+	err := rows.Scan(&message.ID, &message.Content, &images, &message.DateCreate, &author.ID, &author.Name, &dateDelivered, &dateRead, &dateEdit, &dateDelete)
 	message.Images = getImagesArray(images)
+	message.DateDelivered = dateDelivered.String
+	message.DateRead = dateRead.String
+	message.DateEdit = dateEdit.String
+	message.DateDelete = dateDelete.String
 	message.Author = &author
 	chat.Messages = append(chat.Messages, message)
 	return err
@@ -285,7 +337,7 @@ used in InsertChatMessage
 */
 func (f *ForumModel) getChatMembersID(chatID int, authorID int) (int, error) {
 	var id int
-	q := `SELECT id FROM chat_members WHERE chatID=? AND userID=? `
+	q := `SELECT id FROM chat_members WHERE chatID=? AND userID=? AND active`
 	row := f.DB.QueryRow(q, chatID, authorID)
 
 	err := row.Scan(&id)
@@ -298,3 +350,206 @@ func (f *ForumModel) getChatMembersID(chatID int, authorID int) (int, error) {
 
 	return id, nil
 }
+
+/*
+marks all messages of the chat as read by the user
+*/
+func (f *ForumModel) MarkChatAsRead(chatID, userID int) error {
+	q := `UPDATE chat_members SET lastReadMessageID = 
+			(SELECT ifnull(max(ms.id), 0) FROM chat_messages ms 
+				LEFT JOIN chat_members mb ON ms.chat_membersID=mb.id 
+				WHERE mb.chatID=?) 
+		  WHERE chatID=? AND userID=?`
+	res, err := f.DB.Exec(q, chatID, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	return f.checkUnique(res)
+}
+
+/*
+marks the message with the given id and all the previouse messages of the chat as read by the user.
+It never moves the read state of the user back.
+*/
+func (f *ForumModel) SetLastReadChatMessage(chatID, userID, messageID int) error {
+	q := `UPDATE chat_members SET lastReadMessageID=? WHERE chatID=? AND userID=? AND lastReadMessageID<?`
+	_, err := f.DB.Exec(q, messageID, chatID, userID, messageID)
+	return err
+}
+
+/*
+returns the list of the user's chats which have messages not read by the user,
+with the number of the unread messages in each chat.
+*/
+func (f *ForumModel) GetUnreadChats(userID int) ([]model.UnreadChat, error) {
+	q := `SELECT ch.id, ifnull(ch.title, ch.name), ch.type, 
+			(CASE WHEN ch.type=? THEN (SELECT userID FROM chat_members WHERE chatID=ch.id AND userID!=mb.userID) ELSE 0 END),
+			count(ms.id)
+		FROM chat_members mb
+		LEFT JOIN chats ch ON ch.id=mb.chatID
+		INNER JOIN chat_members author ON author.chatID=mb.chatID AND author.id!=mb.id
+		INNER JOIN chat_messages ms ON ms.chat_membersID=author.id AND ms.id>mb.lastReadMessageID AND ms.dateDelete IS NULL
+		WHERE mb.userID=? AND mb.active
+		GROUP BY ch.id
+		ORDER BY max(ms.dateCreate) DESC`
+
+	rows, err := f.DB.Query(q, model.PRIVATE_CHAT, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var unreadChats []model.UnreadChat
+	for rows.Next() {
+		var unreadChat model.UnreadChat
+		var otherUserID sql.NullInt64
+		err := rows.Scan(&unreadChat.ChatID, &unreadChat.ChatName, &unreadChat.Type, &otherUserID, &unreadChat.Unread)
+		if err != nil {
+			return nil, err
+		}
+		unreadChat.UserID = int(otherUserID.Int64)
+		unreadChats = append(unreadChats, unreadChat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return unreadChats, nil
+}
+
+/*
+returns maximum 'messageNumbers' last messages which were sent to the user and have been neither read
+nor delivered to the user yet. The messages of the authors blocked by the user are skipped.
+The messages are sorted by date of creation in ascending order.
+If messageNumbers is less or equal to 0 it will ignore the condition.
+*/
+func (f *ForumModel) GetUndeliveredChatMessages(userID int, messageNumbers int) ([]model.ChatMessage, error) {
+	q := `SELECT ms.id, mb.chatID, ms.content, ms.images, ms.dateCreate, author.userID, u.name
+		FROM chat_members mb
+		INNER JOIN chat_members author ON author.chatID=mb.chatID AND author.id!=mb.id
+		INNER JOIN chat_messages ms ON ms.chat_membersID=author.id AND ms.id>mb.lastReadMessageID AND ms.dateDelete IS NULL
+		LEFT JOIN users u ON u.id=author.userID
+		WHERE mb.userID=? AND mb.active
+			AND NOT EXISTS (SELECT 1 FROM chat_messages_receipts r
+				WHERE r.messageID=ms.id AND r.userID=mb.userID AND r.dateDelivered IS NOT NULL)
+			AND author.userID NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID=mb.userID)
+		ORDER BY ms.dateCreate DESC, ms.id DESC`
+	arguments := []any{userID}
+	if messageNumbers > 0 {
+		q += ` LIMIT ?`
+		arguments = append(arguments, messageNumbers)
+	}
+
+	rows, err := f.DB.Query(q, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []model.ChatMessage
+	authors := make(map[int]*model.User)
+	for rows.Next() {
+		message := model.ChatMessage{}
+		author := &model.User{}
+		var images sql.NullString
+		err := rows.Scan(&message.ID, &message.ChatID, &message.Content, &images, &message.DateCreate, &author.ID, &author.Name)
+		if err != nil {
+			return nil, err
+		}
+		message.Images = getImagesArray(images)
+		if existingAuthor, ok := authors[author.ID]; ok {
+			author = existingAuthor
+		} else {
+			authors[author.ID] = author
+		}
+		message.Author = author
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// the oldest message goes first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+/*
+returns the chat id and the author's id of the chat message with the given id
+*/
+func (f *ForumModel) GetChatMessageAuthor(messageID int) (int, int, error) {
+	var chatID, authorID int
+	q := `SELECT mb.chatID, mb.userID FROM chat_messages ms 
+		  INNER JOIN chat_members mb ON ms.chat_membersID=mb.id 
+		  WHERE ms.id=?`
+	row := f.DB.QueryRow(q, messageID)
+
+	err := row.Scan(&chatID, &authorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, model.ErrNoRecord
+		}
+		return 0, 0, err
+	}
+
+	return chatID, authorID, nil
+}
+
+/*
+returns true if the user is a member of the chat
+*/
+func (f *ForumModel) IsChatMember(chatID, userID int) (bool, error) {
+	_, err := f.getChatMembersID(chatID, userID)
+	if errors.Is(err, model.ErrNoRecord) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+saves the date when the chat message was delivered to the user.
+Returns false if the message had been already marked as delivered to the user before.
+*/
+func (f *ForumModel) SetChatMessageDelivered(messageID, userID int, date time.Time) (bool, error) {
+	return f.setChatMessageReceipt("dateDelivered", messageID, userID, date)
+}
+
+/*
+saves the date when the chat message was read by the user, the message is marked as delivered too.
+Returns false if the message had been already marked as read by the user before.
+*/
+func (f *ForumModel) SetChatMessageRead(messageID, userID int, date time.Time) (bool, error) {
+	_, err := f.setChatMessageReceipt("dateDelivered", messageID, userID, date)
+	if err != nil {
+		return false, err
+	}
+	return f.setChatMessageReceipt("dateRead", messageID, userID, date)
+}
+
+/*
+sets the date field of a receipt for the message and the user if the field has not been set yet.
+Returns true if the field was set.
+*/
+func (f *ForumModel) setChatMessageReceipt(field string, messageID, userID int, date time.Time) (bool, error) {
+	q := `INSERT INTO chat_messages_receipts (messageID, userID, ` + field + `) VALUES (?,?,?)
+		  ON CONFLICT (messageID, userID) DO UPDATE SET ` + field + `=excluded.` + field + ` WHERE ` + field + ` IS NULL`
+	res, err := f.DB.Exec(q, messageID, userID, date)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
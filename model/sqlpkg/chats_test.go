@@ -1,8 +1,10 @@
 package sqlpkg
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"forum/model"
 )
@@ -110,4 +112,261 @@ func TestGetLastMessageDateFromUserToRecipientt(t *testing.T) {
 	fmt.Println("---------------")
 
 	fmt.Printf("from user %d to user %d mes date: %v\n", 4,10, date)
-}
\ No newline at end of file
+}
+func TestUnreadChatMessages(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	chatID, _, err := f.GetPrivateChat(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.MarkChatAsRead(chatID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := f.InsertChatMessage(chatID, 4, "are you there?", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeleteChatMessage(id)
+
+	unreadChats, err := f.GetUnreadChats(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("unread chats of user 5: %#v\n", unreadChats)
+	if len(unreadChats) != 1 || unreadChats[0].ChatID != chatID || unreadChats[0].UserID != 4 || unreadChats[0].Unread != 1 {
+		t.Fatalf("expected 1 unread message in the chat %d from the user 4, got %#v", chatID, unreadChats)
+	}
+
+	messages, err := f.GetUndeliveredChatMessages(5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].ID != id || messages[0].Author.ID != 4 {
+		t.Fatalf("expected the unread message %d from the user 4, got %v", id, messages)
+	}
+
+	// the delivered message is not sent again
+	_, err = f.SetChatMessageDelivered(id, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err = f.GetUndeliveredChatMessages(5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("expected no undelivered messages after the delivery, got %v", messages)
+	}
+
+	// the messages of a blocked author are not sent
+	blockedID, err := f.InsertChatMessage(chatID, 4, "are you still there?", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeleteChatMessage(blockedID)
+	err = f.BlockUser(5, 4, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnblockUser(5, 4)
+	messages, err = f.GetUndeliveredChatMessages(5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("expected no undelivered messages from the blocked user, got %v", messages)
+	}
+
+	err = f.MarkChatAsRead(chatID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreadChats, err = f.GetUnreadChats(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unreadChats) != 0 {
+		t.Fatalf("expected no unread chats after reading, got %#v", unreadChats)
+	}
+}
+
+func TestChatMessageReceipts(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	chatID, _, err := f.GetPrivateChat(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := f.InsertChatMessage(chatID, 4, "did you get it?", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeleteChatMessage(id)
+
+	gotChatID, authorID, err := f.GetChatMessageAuthor(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotChatID != chatID || authorID != 4 {
+		t.Fatalf("expected chat %d and author 4, got chat %d and author %d", chatID, gotChatID, authorID)
+	}
+
+	isNew, err := f.SetChatMessageDelivered(id, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isNew {
+		t.Fatal("the first delivery receipt is expected to be new")
+	}
+	isNew, err = f.SetChatMessageDelivered(id, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isNew {
+		t.Fatal("the second delivery receipt is expected to be ignored")
+	}
+	isNew, err = f.SetChatMessageRead(id, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isNew {
+		t.Fatal("the first read receipt is expected to be new")
+	}
+
+	chat, err := f.GetPrivateChatMessagesByChatId(chatID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("%s\n", chat.String())
+	if chat.Messages[0].ID != id || chat.Messages[0].DateDelivered == "" || chat.Messages[0].DateRead == "" {
+		t.Fatalf("expected the message %d to be delivered and read, got %#v", id, chat.Messages[0])
+	}
+}
+
+func TestEditAndDeleteChatMessage(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	chatID, _, err := f.GetPrivateChat(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := f.InsertChatMessage(chatID, 4, "helo", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeleteChatMessage(id)
+
+	err = f.EditChatMessage(id, "hello", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat, err := f.GetPrivateChatMessagesByChatId(chatID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.Messages[0].Content != "hello" || chat.Messages[0].DateEdit == "" {
+		t.Fatalf("expected the message %d to be edited, got %#v", id, chat.Messages[0])
+	}
+
+	err = f.MarkChatMessageDeleted(id, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat, err = f.GetPrivateChatMessagesByChatId(chatID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.Messages[0].ID != id || chat.Messages[0].Content != "" || chat.Messages[0].DateDelete == "" {
+		t.Fatalf("expected the message %d to be a tombstone, got %#v", id, chat.Messages[0])
+	}
+
+	err = f.EditChatMessage(id, "hello again", time.Now())
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Fatalf("editing of a deleted message: expected error %v, got %v", model.ErrNoRecord, err)
+	}
+	err = f.MarkChatMessageDeleted(id, time.Now())
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Fatalf("deleting of a deleted message: expected error %v, got %v", model.ErrNoRecord, err)
+	}
+}
+
+func TestGroupChat(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	chat, err := f.CreateGroupChat("hikers", 5, []int{6, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`DELETE FROM chats WHERE id=?`, chat.ID)
+	fmt.Printf("%s\n", chat.String())
+	if chat.Type != model.GROUP_CHAT || chat.Title != "hikers" || chat.CreatorID != 5 || len(chat.Members) != 3 {
+		t.Fatalf("unexpected group chat: %s", chat)
+	}
+
+	err = f.AddChatMember(chat.ID, 6)
+	if err != model.ErrUnique {
+		t.Fatalf("adding an existing member: expected error %v, got %v", model.ErrUnique, err)
+	}
+
+	err = f.RemoveChatMember(chat.ID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isMember, err := f.IsChatMember(chat.ID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isMember {
+		t.Fatal("the removed user is still a member of the chat")
+	}
+
+	err = f.AddChatMember(chat.ID, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chats, err := f.GetUsersGroupChats(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chats) == 0 || chats[0].ID != chat.ID {
+		t.Fatalf("expected the group chat %d in the user's list, got %v", chat.ID, chats)
+	}
+	err = f.LeaveGroupChat(chat.ID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat, err = f.GetChat(chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.CreatorID != 6 || len(chat.Members) != 2 {
+		t.Fatalf("after the creator left expected the creator 6 and 2 members, got %s", chat)
+	}
+	err = f.LeaveGroupChat(chat.ID, 5)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Fatalf("leaving the chat twice: expected error %v, got %v", model.ErrNoRecord, err)
+	}
+}
@@ -31,6 +31,11 @@ func (f *ForumModel) InsertComment(postID int, content string, images []string,
 		return 0, err
 	}
 
+	err = f.updateSearchIndex(f.DB, model.SEARCH_COMMENT, int(commentID))
+	if err != nil {
+		return 0, err
+	}
+
 	return int(commentID), nil
 }
 
@@ -38,6 +43,10 @@ func (f *ForumModel) InsertComment(postID int, content string, images []string,
 modify a comment with the given id
 */
 func (f *ForumModel) ModifyComment(id int, content string, images []string) error {
+	return f.modifyComment(f.DB, id, content, images)
+}
+
+func (f *ForumModel) modifyComment(db executor, id int, content string, images []string) error {
 	fields := ""
 	fieldsValues := []any{}
 	if content != "" {
@@ -55,12 +64,12 @@ func (f *ForumModel) ModifyComment(id int, content string, images []string) erro
 	fieldsValues = append(fieldsValues, id)
 
 	q := fmt.Sprintf("UPDATE comments SET %s WHERE id=?", fields)
-	_, err := f.DB.Exec(q, fieldsValues...)
+	_, err := db.Exec(q, fieldsValues...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return f.updateSearchIndex(db, model.SEARCH_COMMENT, id)
 }
 
 /*
@@ -104,12 +113,56 @@ func (f *ForumModel) GetCommentByID(id int) (*model.Comment, error) {
 }
 
 /*
-Delete a post from the database (this is "hard" delete, we should use "soft" delete instead)
+saves the current version of the comment to the edit history and changes the comment's content.
+Returns ErrNoRecord if there is no such comment
+*/
+func (f *ForumModel) EditComment(id int, content string, dateEdit time.Time) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = f.saveToEditHistory(tx, model.COMMENT, id, dateEdit)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.modifyComment(tx, id, content, nil)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+Delete a comment from the database with its likes and edit history (this is "hard" delete, we should use "soft" delete instead)
 */
 func (f *ForumModel) Delete(id int) error {
-	_, err := f.DB.Exec("DELETE FROM comments WHERE id = ?", id)
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = f.deleteComment(tx, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+func (f *ForumModel) deleteComment(db executor, id int) error {
+	err := f.deleteLikeByMessageID(db, model.COMMENT+"s_likes", id)
+	if err != nil {
+		return fmt.Errorf("comment's likes delete failed: func '%s' : %w", logger.GetCurrentFuncName(), err)
+	}
+	_, err = db.Exec("DELETE FROM comments WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("comment delete failed: func '%s' : %w", logger.GetCurrentFuncName(), err)
 	}
-	return nil
+	err = f.deleteEditHistory(db, model.COMMENT, id)
+	if err != nil {
+		return err
+	}
+	return f.deleteFromSearchIndex(db, model.SEARCH_COMMENT, id)
 }
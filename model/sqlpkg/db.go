@@ -16,6 +16,12 @@ type ForumModel struct {
 	DB *sql.DB
 }
 
+// executor runs queries on the DB or in a transaction, the helpers used inside transactions take it
+type executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
 func OpenDB(name, user, pass string) (*sql.DB, error) {
 	// init pull (not connection)
 	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_auth&_auth_user=%s&_auth_pass=%s&_foreign_keys=on", name, user, pass))
@@ -29,6 +35,11 @@ func OpenDB(name, user, pass string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	err = initSearchIndex(db)
+	if err != nil {
+		return nil, handleErrAndCloseDB(db, "search index creating", err)
+	}
+
 	return db, nil
 }
 
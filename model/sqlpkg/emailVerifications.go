@@ -0,0 +1,77 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+adds an email verification token of the user for the email, only the hash of the token is kept in DB
+*/
+func (f *ForumModel) InsertEmailVerification(userID int, email, tokenHash string, dateCreate, dateExpire time.Time) error {
+	q := `INSERT INTO email_verifications (userID, email, tokenHash, dateCreate, dateExpire) VALUES (?,?,?,?,?)`
+	_, err := f.DB.Exec(q, userID, email, tokenHash, dateCreate, dateExpire)
+	return err
+}
+
+/*
+returns the number of email verification tokens created for the user since the given time
+*/
+func (f *ForumModel) CountEmailVerifications(userID int, since time.Time) (int, error) {
+	var count int
+	q := `SELECT count(*) FROM email_verifications WHERE userID=? AND dateCreate>?`
+	err := f.DB.QueryRow(q, userID, since).Scan(&count)
+	return count, err
+}
+
+/*
+uses the email verification token: the token's user becomes verified and all the tokens of the user are deleted.
+Returns the id of the user. Returns ErrNoRecord if there is no such token, the token is expired
+or the token was sent to another email than the current email of the user
+*/
+func (f *ForumModel) VerifyEmail(tokenHash string, now time.Time) (int, error) {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+	q := `SELECT ev.userID FROM email_verifications ev JOIN users u ON u.id=ev.userID
+		WHERE ev.tokenHash=? AND ev.dateExpire>? AND ev.email=u.email`
+	err = tx.QueryRow(q, tokenHash, now).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, rollback(tx, model.ErrNoRecord)
+	}
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	q = `UPDATE users SET verified=TRUE WHERE id=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	q = `DELETE FROM email_verifications WHERE userID=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	return userID, tx.Commit()
+}
+
+/*
+returns true if the user verified the email. Returns ErrNoRecord if there is no such user
+*/
+func (f *ForumModel) IsUserVerified(userID int) (bool, error) {
+	var verified bool
+	err := f.DB.QueryRow(`SELECT verified FROM users WHERE id=?`, userID).Scan(&verified)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, model.ErrNoRecord
+	}
+	return verified, err
+}
@@ -0,0 +1,100 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	user, err := f.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`UPDATE users SET email=?, verified=? WHERE id=?`, user.Email, user.Verified, userID)
+	defer f.DB.Exec(`DELETE FROM email_verifications WHERE userID=?`, userID)
+
+	// the changed email must be verified again
+	err = f.ChangeUsersEmail(userID, user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := f.IsUserVerified(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified {
+		t.Errorf("the user is verified after changing the email")
+	}
+
+	now := time.Now()
+	err = f.InsertEmailVerification(userID, user.Email, "expired token hash", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.InsertEmailVerification(userID, user.Email, "token hash", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := f.CountEmailVerifications(userID, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d email verifications in the last hour, want 1", count)
+	}
+
+	_, err = f.VerifyEmail("expired token hash", now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using an expired token: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	gotUserID, err := f.VerifyEmail("token hash", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUserID != userID {
+		t.Errorf("got the user %d, want %d", gotUserID, userID)
+	}
+	verified, err = f.IsUserVerified(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Errorf("the user is not verified")
+	}
+	_, err = f.VerifyEmail("token hash", now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using a token twice: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	// a token sent to the old email cannot verify the changed one
+	err = f.InsertEmailVerification(userID, user.Email, "old email token hash", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.ChangeUsersEmail(userID, "changed."+user.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.VerifyEmail("old email token hash", now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using a token of the old email: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	verified, err = f.IsUserVerified(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified {
+		t.Errorf("the changed email is verified by a token of the old email")
+	}
+}
@@ -0,0 +1,236 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"forum/model"
+)
+
+/*
+creates a new group chat with the given title, the creator and the members are added to the chat.
+Returns the chat with its members
+*/
+func (f *ForumModel) CreateGroupChat(title string, creatorID int, membersID []int) (*model.Chat, error) {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// group chats are distinguished by their ids, the name is kept only for the UNIQUE (name, type) constraint
+	name := fmt.Sprintf("%d-%d", creatorID, time.Now().UnixNano())
+	q := `INSERT INTO chats (name, type, title, creatorID) VALUES (?,?,?,?)`
+	res, err := tx.Exec(q, name, model.GROUP_CHAT, title, creatorID)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	chatID := int(id)
+
+	q = `INSERT INTO chat_members (chatID, userID) VALUES (?,?)`
+	_, err = tx.Exec(q, chatID, creatorID)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	for _, memberID := range membersID {
+		if memberID == creatorID {
+			continue
+		}
+		_, err = tx.Exec(q, chatID, memberID)
+		if err != nil {
+			return nil, rollback(tx, fmt.Errorf("adding the member %d failed: %w", memberID, err))
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return f.GetChat(chatID)
+}
+
+/*
+returns the chat with the given id and its active members, without messages
+*/
+func (f *ForumModel) GetChat(chatID int) (*model.Chat, error) {
+	q := `SELECT id, name, type, title, creatorID FROM chats WHERE id=?`
+	chat := &model.Chat{Messages: []model.ChatMessage{}}
+	var title sql.NullString
+	var creatorID sql.NullInt64
+	row := f.DB.QueryRow(q, chatID)
+	err := row.Scan(&chat.ID, &chat.Name, &chat.Type, &title, &creatorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrNoRecord
+		}
+		return nil, err
+	}
+	chat.Title = title.String
+	chat.CreatorID = int(creatorID.Int64)
+
+	chat.Members, err = f.GetChatMembers(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	return chat, nil
+}
+
+/*
+returns the active members of the chat sorted by their names
+*/
+func (f *ForumModel) GetChatMembers(chatID int) ([]*model.User, error) {
+	q := `SELECT u.id, u.name FROM chat_members mb
+		  LEFT JOIN users u ON u.id=mb.userID
+		  WHERE mb.chatID=? AND mb.active
+		  ORDER BY lower(u.name)`
+	rows, err := f.DB.Query(q, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*model.User
+	for rows.Next() {
+		member := &model.User{}
+		err := rows.Scan(&member.ID, &member.Name)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+/*
+returns the group chats where the user is an active member, the chats are sorted by the date of the last message
+*/
+func (f *ForumModel) GetUsersGroupChats(userID int) ([]*model.Chat, error) {
+	q := `SELECT ch.id FROM chats ch
+		  INNER JOIN chat_members mb ON mb.chatID=ch.id AND mb.userID=? AND mb.active
+		  LEFT JOIN chat_members authors ON authors.chatID=ch.id
+		  LEFT JOIN chat_messages ms ON ms.chat_membersID=authors.id
+		  WHERE ch.type=?
+		  GROUP BY ch.id
+		  ORDER BY max(ms.dateCreate) DESC, ch.id DESC`
+	rows, err := f.DB.Query(q, userID, model.GROUP_CHAT)
+	if err != nil {
+		return nil, err
+	}
+
+	var chatsID []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		chatsID = append(chatsID, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	chats := make([]*model.Chat, 0, len(chatsID))
+	for _, id := range chatsID {
+		chat, err := f.GetChat(id)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+
+	return chats, nil
+}
+
+/*
+adds the user to the chat. If the user was a member of the chat before, the membership is restored.
+Messages sent to the chat before the user was added are not unread for the user
+*/
+func (f *ForumModel) AddChatMember(chatID, userID int) error {
+	q := `INSERT INTO chat_members (chatID, userID, lastReadMessageID) VALUES (?,?,
+			(SELECT ifnull(max(ms.id), 0) FROM chat_messages ms
+				LEFT JOIN chat_members mb ON ms.chat_membersID=mb.id WHERE mb.chatID=?))
+		  ON CONFLICT (chatID, userID) DO UPDATE SET active=TRUE, lastReadMessageID=excluded.lastReadMessageID WHERE NOT active`
+	res, err := f.DB.Exec(q, chatID, userID, chatID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUnique // the user is an active member already
+	}
+	return nil
+}
+
+/*
+removes the user from the chat. The messages of the user are kept in the chat
+*/
+func (f *ForumModel) RemoveChatMember(chatID, userID int) error {
+	q := `UPDATE chat_members SET active=FALSE WHERE chatID=? AND userID=? AND active`
+	res, err := f.DB.Exec(q, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	return f.checkUnique(res)
+}
+
+/*
+removes the user from the chat. If the user is the creator of the chat, the member who has been in the chat
+the longest becomes the creator, so the chat keeps someone who can manage its members
+*/
+func (f *ForumModel) LeaveGroupChat(chatID, userID int) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	q := `UPDATE chat_members SET active=FALSE WHERE chatID=? AND userID=? AND active`
+	res, err := tx.Exec(q, chatID, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.checkUnique(res)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	q = `UPDATE chats SET creatorID=(SELECT userID FROM chat_members WHERE chatID=? AND active ORDER BY id LIMIT 1)
+		 WHERE id=? AND creatorID=?`
+	_, err = tx.Exec(q, chatID, chatID, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+rolls back the transaction and returns the error which caused the rollback
+*/
+func rollback(tx *sql.Tx, err error) error {
+	errRoll := tx.Rollback()
+	if errRoll != nil {
+		return fmt.Errorf("%w, unable to rollback the transaction: %w", err, errRoll)
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"time"
+
+	"forum/model"
+)
+
+/*
+saves the current version of the message of the given type (model.POST or model.COMMENT) to the edit history.
+Returns ErrNoRecord if there is no such message
+*/
+func (f *ForumModel) saveToEditHistory(db executor, messageType string, messageID int, dateEdit time.Time) error {
+	theme := "NULL"
+	if messageType == model.POST {
+		theme = "theme"
+	}
+	q := `INSERT INTO edit_history (messageType, messageID, theme, content, images, dateEdit)
+		  SELECT ?, id, ` + theme + `, content, images, ? FROM ` + messageType + `s WHERE id=?`
+	res, err := db.Exec(q, messageType, dateEdit, messageID)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+returns the previous versions of the message of the given type (model.POST or model.COMMENT), the latest version is the first
+*/
+func (f *ForumModel) GetEditHistory(messageType string, messageID int) ([]model.MessageVersion, error) {
+	q := `SELECT theme, content, images, dateEdit FROM edit_history
+		  WHERE messageType=? AND messageID=?
+		  ORDER BY id DESC`
+	rows, err := f.DB.Query(q, messageType, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []model.MessageVersion{}
+	for rows.Next() {
+		var version model.MessageVersion
+		var theme, images sql.NullString
+		err := rows.Scan(&theme, &version.Content, &images, &version.DateEdit)
+		if err != nil {
+			return nil, err
+		}
+		version.Theme = theme.String
+		version.Images = getImagesArray(images)
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+/*
+removes the edit history of the message of the given type (model.POST or model.COMMENT)
+*/
+func (f *ForumModel) deleteEditHistory(db executor, messageType string, messageID int) error {
+	q := `DELETE FROM edit_history WHERE messageType=? AND messageID=?`
+	_, err := db.Exec(q, messageType, messageID)
+	return err
+}
@@ -11,11 +11,36 @@ import (
 the group of function for getting likes
 ****/
 
+/* returns true if the message of the given type (model.POST or model.COMMENT) with the given id exists*/
+func (f *ForumModel) IsMessageExisting(messageType string, messageID int) (bool, error) {
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM ` + messageType + `s WHERE id=?)`
+	err := f.DB.QueryRow(q, messageID).Scan(&exists)
+	return exists, err
+}
+
+/*
+returns the id of the post, which the message of the given type (model.POST or model.COMMENT) belongs to,
+and the id of the message's author
+*/
+func (f *ForumModel) GetMessagePostAndAuthor(messageType string, messageID int) (int, int, error) {
+	q := `SELECT id, authorID FROM posts WHERE id=?`
+	if messageType == model.COMMENT {
+		q = `SELECT postID, authorID FROM comments WHERE id=?`
+	}
+	var postID, authorID int
+	err := f.DB.QueryRow(q, messageID).Scan(&postID, &authorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, model.ErrNoRecord
+	}
+	return postID, authorID, err
+}
+
 /* returns quantity of likes/dislikes from the given table (posts or comments) for the given id of a message*/
 func (f *ForumModel) GetLikes(tableName string, messageID int, userIDForReaction int) ([]int, int8, error) {
 	likes := []int{0, 0}
 	q := `SELECT  count(CASE WHEN like THEN TRUE END) AS likes, count(CASE WHEN NOT like THEN TRUE END)  AS dislikes,   
-	(SELECT like FROM posts_likes WHERE userID = ? AND messageID = ?) AS user_like 
+	(SELECT like FROM ` + tableName + ` WHERE userID = ? AND messageID = ?) AS user_like 
 	FROM ` + tableName + ` WHERE messageID=? `
 	row := f.DB.QueryRow(q, userIDForReaction, messageID, messageID)
 
@@ -141,9 +166,9 @@ func (f *ForumModel) DeleteCommentLike(id int) error {
 }
 
 /*deletes a row from the given table by message ID.*/
-func (f *ForumModel) deleteLikeByMessageID(tableName string, id int) error {
+func (f *ForumModel) deleteLikeByMessageID(db executor, tableName string, id int) error {
 	q := `DELETE FROM ` + tableName + ` WHERE messageID=?`
-	_, err := f.DB.Exec(q, id)
+	_, err := db.Exec(q, id)
 	if err != nil {
 		return err
 	}
@@ -152,9 +177,9 @@ func (f *ForumModel) deleteLikeByMessageID(tableName string, id int) error {
 }
 
 func (f *ForumModel) DeletePostLikeByMessageID(id int) error {
-	return f.deleteLikeByMessageID(model.POST+"s_likes"+"s_likes", id)
+	return f.deleteLikeByMessageID(f.DB, model.POST+"s_likes", id)
 }
 
 func (f *ForumModel) DeleteCommentLikeByMessageID(id int) error {
-	return f.deleteLikeByMessageID(model.COMMENT+"s_likes", id)
+	return f.deleteLikeByMessageID(f.DB, model.COMMENT+"s_likes", id)
 }
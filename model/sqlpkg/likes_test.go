@@ -1,8 +1,11 @@
 package sqlpkg
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+
+	"forum/model"
 )
 
 func TestLikes(t *testing.T) {
@@ -109,3 +112,68 @@ func (f *ForumModel) printLikes(table string) error {
 
 	return nil
 }
+
+func TestIsMessageExisting(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	f := ForumModel{db}
+
+	cases := []struct {
+		messageType string
+		id          int
+		want        bool
+	}{
+		{model.POST, 1, true},
+		{model.POST, -1, false},
+		{model.COMMENT, -1, false},
+	}
+	for i, c := range cases {
+		exists, err := f.IsMessageExisting(c.messageType, c.id)
+		if err != nil {
+			t.Fatalf("# %d: %v", i, err)
+		}
+		if exists != c.want {
+			t.Errorf("# %d: %s %d exists = %v, want %v", i, c.messageType, c.id, exists, c.want)
+		}
+	}
+}
+
+func TestGetMessagePostAndAuthor(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	f := ForumModel{db}
+
+	var commentID, commentPostID, commentAuthorID int
+	err = f.DB.QueryRow(`SELECT id, postID, authorID FROM comments LIMIT 1`).Scan(&commentID, &commentPostID, &commentAuthorID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID, authorID, err := f.GetMessagePostAndAuthor(model.COMMENT, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postID != commentPostID || authorID != commentAuthorID {
+		t.Errorf("comment %d: got the post %d and the author %d, want %d and %d", commentID, postID, authorID, commentPostID, commentAuthorID)
+	}
+
+	postID, _, err = f.GetMessagePostAndAuthor(model.POST, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postID != 1 {
+		t.Errorf("post 1: got the post %d", postID)
+	}
+
+	_, _, err = f.GetMessagePostAndAuthor(model.COMMENT, -1)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("a missing comment: expected error %v, got %v", model.ErrNoRecord, err)
+	}
+}
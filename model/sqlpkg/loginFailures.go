@@ -0,0 +1,69 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"time"
+)
+
+/*
+adds a failed login from the IP address. The userID is 0 if there is no user with the given name or email
+*/
+func (f *ForumModel) InsertLoginFailure(userID int, ip string, dateCreate time.Time) error {
+	q := `INSERT INTO login_failures (userID, ip, dateCreate) VALUES (?,?,?)`
+	_, err := f.DB.Exec(q, sql.NullInt64{Int64: int64(userID), Valid: userID > 0}, ip, dateCreate)
+	return err
+}
+
+/*
+returns the times of the failed logins to the user's account since the given time, the latest go first
+*/
+func (f *ForumModel) GetLoginFailuresByUser(userID int, since time.Time) ([]time.Time, error) {
+	q := `SELECT dateCreate FROM login_failures WHERE userID=? AND dateCreate>? ORDER BY dateCreate DESC`
+	return f.getLoginFailures(q, userID, since)
+}
+
+/*
+returns the times of the failed logins from the IP address since the given time, the latest go first
+*/
+func (f *ForumModel) GetLoginFailuresByIP(ip string, since time.Time) ([]time.Time, error) {
+	q := `SELECT dateCreate FROM login_failures WHERE ip=? AND dateCreate>? ORDER BY dateCreate DESC`
+	return f.getLoginFailures(q, ip, since)
+}
+
+func (f *ForumModel) getLoginFailures(q string, args ...any) ([]time.Time, error) {
+	rows, err := f.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []time.Time
+	for rows.Next() {
+		var dateCreate time.Time
+		err = rows.Scan(&dateCreate)
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, dateCreate)
+	}
+	return failures, rows.Err()
+}
+
+/*
+deletes the failed logins to the user's account, e.g. after a successful login
+*/
+func (f *ForumModel) DeleteLoginFailures(userID int) error {
+	q := `DELETE FROM login_failures WHERE userID=?`
+	_, err := f.DB.Exec(q, userID)
+	return err
+}
+
+/*
+keeps the lockout of the logins to the user's account or from the IP address for the audit.
+The userID is 0 if the IP address is locked out
+*/
+func (f *ForumModel) InsertLoginLockout(userID int, ip string, failures int, dateCreate, dateExpire time.Time) error {
+	q := `INSERT INTO login_lockouts (userID, ip, failures, dateCreate, dateExpire) VALUES (?,?,?,?,?)`
+	_, err := f.DB.Exec(q, sql.NullInt64{Int64: int64(userID), Valid: userID > 0}, ip, failures, dateCreate, dateExpire)
+	return err
+}
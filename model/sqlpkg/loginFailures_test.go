@@ -0,0 +1,76 @@
+package sqlpkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginFailures(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	const ip = "192.0.2.10"
+	defer f.DB.Exec(`DELETE FROM login_failures WHERE ip=?`, ip)
+	defer f.DB.Exec(`DELETE FROM login_lockouts WHERE ip=?`, ip)
+
+	now := time.Now()
+	for i, failure := range []struct {
+		userID int
+		date   time.Time
+	}{
+		{userID, now.Add(-2 * time.Hour)},
+		{userID, now.Add(-2 * time.Minute)},
+		{userID, now.Add(-time.Minute)},
+		{0, now},
+	} {
+		err = f.InsertLoginFailure(failure.userID, ip, failure.date)
+		if err != nil {
+			t.Fatalf("# %d: %v", i, err)
+		}
+	}
+
+	failures, err := f.GetLoginFailuresByUser(userID, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 2 || !failures[0].Equal(now.Add(-time.Minute)) {
+		t.Errorf("got the user's failures %v, want the last 2, the latest first", failures)
+	}
+	failures, err = f.GetLoginFailuresByIP(ip, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 3 || !failures[0].Equal(now) {
+		t.Errorf("got the IP's failures %v, want the last 3, the latest first", failures)
+	}
+
+	err = f.DeleteLoginFailures(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures, err = f.GetLoginFailuresByIP(ip, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 {
+		t.Errorf("got %d failures from the IP after deleting the user's failures, want 1", len(failures))
+	}
+
+	err = f.InsertLoginLockout(userID, ip, 10, now, now.Add(15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lockouts int
+	err = f.DB.QueryRow(`SELECT count(*) FROM login_lockouts WHERE userID=? AND ip=?`, userID, ip).Scan(&lockouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockouts != 1 {
+		t.Errorf("got %d lockouts, want 1", lockouts)
+	}
+}
@@ -0,0 +1,226 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+returns the current role of the user
+*/
+func (f *ForumModel) GetUserRole(userID int) (model.Role, error) {
+	var role model.Role
+	err := f.DB.QueryRow(`SELECT role FROM users WHERE id=?`, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return role, model.ErrNoRecord
+	}
+	return role, err
+}
+
+/*
+changes the role of the user. Returns ErrNoRecord if there is no such user
+*/
+func (f *ForumModel) SetUserRole(userID int, role model.Role) error {
+	return f.changeUsersField(userID, "role", string(role))
+}
+
+/*
+bans the user, deletes all the user's sessions and adds the entry to the moderation log.
+Returns ErrNoRecord if there is no such user or the user is banned already
+*/
+func (f *ForumModel) BanUser(userID int, dateBan time.Time, logEntry *model.ModerationLogEntry) error {
+	q := `UPDATE users SET dateBan=? WHERE id=? AND dateBan IS NULL`
+	return f.moderateUser(userID, true, logEntry, q, dateBan, userID)
+}
+
+/*
+lifts the ban of the user and adds the entry to the moderation log.
+Returns ErrNoRecord if there is no such user or the user is not banned
+*/
+func (f *ForumModel) UnbanUser(userID int, logEntry *model.ModerationLogEntry) error {
+	q := `UPDATE users SET dateBan=NULL WHERE id=? AND dateBan IS NOT NULL`
+	return f.moderateUser(userID, false, logEntry, q, userID)
+}
+
+/*
+suspends the user until the given time, deletes all the user's sessions and adds the entry to the moderation log.
+Returns ErrNoRecord if there is no such user
+*/
+func (f *ForumModel) SuspendUser(userID int, until time.Time, logEntry *model.ModerationLogEntry) error {
+	return f.setUserSanction(userID, "suspendedUntil", until, true, logEntry)
+}
+
+/*
+lifts the suspension of the user and adds the entry to the moderation log.
+Returns ErrNoRecord if there is no such user or the user is not suspended
+*/
+func (f *ForumModel) UnsuspendUser(userID int, logEntry *model.ModerationLogEntry) error {
+	return f.liftUserSanction(userID, "suspendedUntil", logEntry)
+}
+
+/*
+mutes the user until the given time: the user can read but cannot post, comment or chat.
+The entry is added to the moderation log. Returns ErrNoRecord if there is no such user
+*/
+func (f *ForumModel) MuteUser(userID int, until time.Time, logEntry *model.ModerationLogEntry) error {
+	return f.setUserSanction(userID, "mutedUntil", until, false, logEntry)
+}
+
+/*
+lifts the mute of the user and adds the entry to the moderation log.
+Returns ErrNoRecord if there is no such user or the user is not muted
+*/
+func (f *ForumModel) UnmuteUser(userID int, logEntry *model.ModerationLogEntry) error {
+	return f.liftUserSanction(userID, "mutedUntil", logEntry)
+}
+
+/*
+returns the current ban, suspension and mute of the user
+*/
+func (f *ForumModel) GetUserSanctions(userID int) (model.Sanctions, error) {
+	var sanctions model.Sanctions
+	q := `SELECT dateBan IS NOT NULL, suspendedUntil, mutedUntil FROM users WHERE id=?`
+	err := f.DB.QueryRow(q, userID).Scan(&sanctions.Banned, &sanctions.SuspendedUntil, &sanctions.MutedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sanctions, model.ErrNoRecord
+	}
+	return sanctions, err
+}
+
+func (f *ForumModel) setUserSanction(userID int, field string, until time.Time, logout bool, logEntry *model.ModerationLogEntry) error {
+	q := `UPDATE users SET ` + field + `=? WHERE id=?`
+	return f.moderateUser(userID, logout, logEntry, q, until, userID)
+}
+
+func (f *ForumModel) liftUserSanction(userID int, field string, logEntry *model.ModerationLogEntry) error {
+	q := `UPDATE users SET ` + field + `=NULL WHERE id=? AND ` + field + ` IS NOT NULL`
+	return f.moderateUser(userID, false, logEntry, q, userID)
+}
+
+/*
+changes the user by the query in a transaction with deleting the user's sessions, if 'logout' is true,
+and with adding the entry to the moderation log, so the action is never applied without its log entry.
+Returns ErrNoRecord if the query changes nothing
+*/
+func (f *ForumModel) moderateUser(userID int, logout bool, logEntry *model.ModerationLogEntry, q string, args ...any) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(q, args...)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.checkUnique(res)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	if logout {
+		_, err = tx.Exec(`DELETE FROM usersessions WHERE userID=?`, userID)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	_, err = f.insertModerationLog(tx, logEntry.Moderator.ID, logEntry.Action, logEntry.TargetType, logEntry.TargetID, logEntry.Reason, logEntry.Date)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+locks or unlocks the post, no new comments can be added to a locked post.
+Returns ErrNoRecord if there is no such post or the post is locked/unlocked already
+*/
+func (f *ForumModel) LockPost(postID int, locked bool) error {
+	q := `UPDATE posts SET locked=? WHERE id=? AND locked!=?`
+	res, err := f.DB.Exec(q, locked, postID, locked)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+returns true if the post is locked. Returns ErrNoRecord if there is no such post
+*/
+func (f *ForumModel) IsPostLocked(postID int) (bool, error) {
+	var locked bool
+	err := f.DB.QueryRow(`SELECT locked FROM posts WHERE id=?`, postID).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, model.ErrNoRecord
+	}
+	return locked, err
+}
+
+/*
+adds the action of a moderator to the moderation log, returns the id of the log entry
+*/
+func (f *ForumModel) InsertModerationLog(moderatorID int, action, targetType string, targetID int, reason string, date time.Time) (int, error) {
+	return f.insertModerationLog(f.DB, moderatorID, action, targetType, targetID, reason, date)
+}
+
+func (f *ForumModel) insertModerationLog(db executor, moderatorID int, action, targetType string, targetID int, reason string, date time.Time) (int, error) {
+	var reasonInDB sql.NullString
+	if reason != "" {
+		reasonInDB.String = reason
+		reasonInDB.Valid = true
+	}
+	q := `INSERT INTO moderation_log (moderatorID, action, targetType, targetID, reason, date) VALUES (?,?,?,?,?,?)`
+	res, err := db.Exec(q, moderatorID, action, targetType, targetID, reasonInDB, date)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+/*
+returns 'n' entries of the moderation log with ids less than 'beforeID', the latest entries are the first.
+If beforeID is less or equal to 0 it will return the latest entries
+*/
+func (f *ForumModel) GetModerationLog(beforeID, n int) ([]*model.ModerationLogEntry, error) {
+	q := `SELECT l.id, l.moderatorID, u.name, l.action, l.targetType, l.targetID, l.reason, l.date
+		  FROM moderation_log l
+		  LEFT JOIN users u ON u.id=l.moderatorID
+		  WHERE ?<=0 OR l.id<?
+		  ORDER BY l.id DESC
+		  LIMIT ?`
+	rows, err := f.DB.Query(q, beforeID, beforeID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.ModerationLogEntry{}
+	for rows.Next() {
+		entry := &model.ModerationLogEntry{}
+		var moderatorID sql.NullInt64
+		var moderatorName, reason sql.NullString
+		err := rows.Scan(&entry.ID, &moderatorID, &moderatorName, &entry.Action, &entry.TargetType, &entry.TargetID, &reason, &entry.Date)
+		if err != nil {
+			return nil, err
+		}
+		if moderatorID.Valid {
+			entry.Moderator = &model.User{ID: int(moderatorID.Int64), Name: moderatorName.String}
+		}
+		entry.Reason = reason.String
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,163 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestModeration(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID, moderatorID = 3, 1
+	now := time.Now()
+
+	err = f.SetUserRole(userID, model.ROLE_MODERATOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role, err := f.GetUserRole(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != model.ROLE_MODERATOR {
+		t.Errorf("role is '%s', want '%s'", role, model.ROLE_MODERATOR)
+	}
+	err = f.SetUserRole(userID, "superuser")
+	if err == nil {
+		t.Errorf("invalid role is accepted")
+	}
+	err = f.SetUserRole(userID, model.ROLE_USER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.AddUsersSession(userID, &model.UserSession{Uuid: "moderation-test-session", ExpirySession: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logEntry := &model.ModerationLogEntry{Moderator: &model.User{ID: moderatorID}, Action: model.MOD_BAN_USER, TargetType: model.USER, TargetID: userID, Date: now}
+	err = f.BanUser(userID, now, logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := f.GetModerationLog(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != model.MOD_BAN_USER || entries[0].TargetID != userID {
+		t.Errorf("the ban is not added to the moderation log: %+v", entries)
+	}
+	defer f.DB.Exec(`DELETE FROM moderation_log WHERE moderatorID=? AND targetType=? AND targetID=?`, moderatorID, model.USER, userID)
+	user, err := f.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.Banned || user.Uuid != "" {
+		t.Errorf("the user is not banned or has sessions: banned %v, session '%s'", user.Banned, user.Uuid)
+	}
+	err = f.BanUser(userID, now, logEntry)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("banning a banned user: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	err = f.UnbanUser(userID, logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.AddUsersSession(userID, &model.UserSession{Uuid: "suspension-test-session", ExpirySession: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.SuspendUser(userID, now.Add(time.Hour), logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.MuteUser(userID, now.Add(time.Minute), logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err = f.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsSuspended(now) || user.IsSuspended(now.Add(2*time.Hour)) || user.Uuid != "" {
+		t.Errorf("wrong suspension of the user: suspended until %v, session '%s'", user.SuspendedUntil, user.Uuid)
+	}
+	sanctions, err := f.GetUserSanctions(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sanctions.IsMuted(now) || sanctions.IsMuted(now.Add(2*time.Minute)) || sanctions.Banned {
+		t.Errorf("wrong sanctions of the user: %+v", sanctions)
+	}
+	err = f.UnsuspendUser(userID, logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.UnmuteUser(userID, logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.UnmuteUser(userID, logEntry)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("unmuting a not muted user: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	sanctions, err = f.GetUserSanctions(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sanctions.IsSuspended(now) || sanctions.IsMuted(now) {
+		t.Errorf("the sanctions are not lifted: %+v", sanctions)
+	}
+
+	postID, err := f.InsertPost("theme to lock", "content to lock", nil, userID, now, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeletePost(postID)
+	err = f.LockPost(postID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, err := f.IsPostLocked(postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked {
+		t.Errorf("the post %d is not locked", postID)
+	}
+	err = f.LockPost(postID, true)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("locking a locked post: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	id, err := f.InsertModerationLog(moderatorID, model.MOD_LOCK_POST, model.POST, postID, "off-topic", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = f.GetModerationLog(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != id || entries[0].Moderator == nil || entries[0].Moderator.ID != moderatorID ||
+		entries[0].Action != model.MOD_LOCK_POST || entries[0].TargetID != postID || entries[0].Reason != "off-topic" {
+		t.Errorf("wrong moderation log entries: %v", entries)
+	}
+	entries, err = f.GetModerationLog(id, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.ID >= id {
+			t.Errorf("the entry %d is not before %d", entry.ID, id)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+returns the id of the user linked to the account of the OAuth provider.
+Returns ErrNoRecord if the account is not linked to any user
+*/
+func (f *ForumModel) GetUserIDByOAuthAccount(provider, providerUserID string) (int, error) {
+	var userID int
+	q := `SELECT userID FROM user_oauth_accounts WHERE provider=? AND providerUserID=?`
+	err := f.DB.QueryRow(q, provider, providerUserID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, model.ErrNoRecord
+	}
+	return userID, err
+}
+
+/*
+links the account of the OAuth provider to the user. Returns ErrUnique if the account is linked already
+*/
+func (f *ForumModel) LinkOAuthAccount(userID int, provider, providerUserID string, dateCreate time.Time) error {
+	q := `INSERT INTO user_oauth_accounts (provider, providerUserID, userID, dateCreate) VALUES (?,?,?,?)
+		  ON CONFLICT (provider, providerUserID) DO NOTHING`
+	res, err := f.DB.Exec(q, provider, providerUserID, userID, dateCreate)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUnique
+	}
+	return nil
+}
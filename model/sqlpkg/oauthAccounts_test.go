@@ -0,0 +1,47 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestLinkOAuthAccount(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	_, err = f.GetUserIDByOAuthAccount("github", "test account")
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("getting a not linked account: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	err = f.LinkOAuthAccount(userID, "github", "test account", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`DELETE FROM user_oauth_accounts WHERE provider=? AND providerUserID=?`, "github", "test account")
+
+	err = f.LinkOAuthAccount(userID+1, "github", "test account", time.Now())
+	if !errors.Is(err, model.ErrUnique) {
+		t.Errorf("linking a linked account: got error %v, want %v", err, model.ErrUnique)
+	}
+
+	gotUserID, err := f.GetUserIDByOAuthAccount("github", "test account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUserID != userID {
+		t.Errorf("got the user %d, want %d", gotUserID, userID)
+	}
+	_, err = f.GetUserIDByOAuthAccount("google", "test account")
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("getting an account of another provider: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
@@ -0,0 +1,70 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+adds a password reset token of the user, only the hash of the token is kept in DB
+*/
+func (f *ForumModel) InsertPasswordReset(userID int, tokenHash string, dateCreate, dateExpire time.Time) error {
+	q := `INSERT INTO password_resets (userID, tokenHash, dateCreate, dateExpire) VALUES (?,?,?,?)`
+	_, err := f.DB.Exec(q, userID, tokenHash, dateCreate, dateExpire)
+	return err
+}
+
+/*
+returns the number of password reset tokens created for the user since the given time
+*/
+func (f *ForumModel) CountPasswordResets(userID int, since time.Time) (int, error) {
+	var count int
+	q := `SELECT count(*) FROM password_resets WHERE userID=? AND dateCreate>?`
+	err := f.DB.QueryRow(q, userID, since).Scan(&count)
+	return count, err
+}
+
+/*
+uses the password reset token: sets the new password of the token's user, deletes all the user's sessions
+and makes the other tokens of the user unusable. Returns the id of the user.
+Returns ErrNoRecord if there is no such token or the token is used or expired already
+*/
+func (f *ForumModel) ResetPassword(tokenHash string, password []byte, now time.Time) (int, error) {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int
+	q := `SELECT userID FROM password_resets WHERE tokenHash=? AND dateUse IS NULL AND dateExpire>?`
+	err = tx.QueryRow(q, tokenHash, now).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, rollback(tx, model.ErrNoRecord)
+	}
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	q = `UPDATE password_resets SET dateUse=? WHERE userID=? AND dateUse IS NULL`
+	_, err = tx.Exec(q, now, userID)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	q = `UPDATE users SET password=? WHERE id=?`
+	_, err = tx.Exec(q, string(password), userID)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	q = `DELETE FROM usersessions WHERE userID=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return 0, rollback(tx, err)
+	}
+
+	return userID, tx.Commit()
+}
@@ -0,0 +1,77 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestResetPassword(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	user, err := f.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.ChangeUsersPassword(userID, string(user.Password))
+	defer f.DB.Exec(`DELETE FROM password_resets WHERE userID=?`, userID)
+
+	now := time.Now()
+	err = f.InsertPasswordReset(userID, "expired token hash", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.InsertPasswordReset(userID, "token hash", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.InsertPasswordReset(userID, "other token hash", now, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := f.CountPasswordResets(userID, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got %d password resets in the last hour, want 2", count)
+	}
+
+	_, err = f.ResetPassword("expired token hash", []byte("new password"), now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using an expired token: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	gotUserID, err := f.ResetPassword("token hash", []byte("new password"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUserID != userID {
+		t.Errorf("got the user %d, want %d", gotUserID, userID)
+	}
+	changedUser, err := f.GetUserByID(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(changedUser.Password) != "new password" {
+		t.Errorf("the password is not changed")
+	}
+
+	_, err = f.ResetPassword("token hash", []byte("new password"), now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using a token twice: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	_, err = f.ResetPassword("other token hash", []byte("new password"), now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using another token after the reset: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
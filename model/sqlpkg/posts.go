@@ -50,14 +50,20 @@ func (f *ForumModel) InsertPost(theme, content string, images []string, authorID
 		return 0, err
 	}
 
+	err = f.updateSearchIndex(f.DB, model.SEARCH_POST, int(postID))
+	if err != nil {
+		return 0, err
+	}
+
 	return int(postID), nil
 }
 
 /*
-search in the DB a post by the given ID
+search in the DB a post by the given ID.
+Returns ErrNoRecord if the author is blocked by the user 'userID', the comments of the blocked users are skipped
 */
 func (f *ForumModel) GetPostByID(id int, userID int) (*model.Post, error) {
-	query := `SELECT p.id, p.theme, p.content, p.images, p.authorID, u.name, u.dateCreate, c.id, c.name,  p.dateCreate, 
+	query := `SELECT p.id, p.theme, p.content, p.images, p.authorID, u.name, u.dateCreate, c.id, c.name,  p.dateCreate, p.locked,
 				 count(CASE WHEN pl.like THEN TRUE END), count(CASE WHEN NOT pl.like THEN TRUE END), 
 				 (CASE WHEN p.id IN (SELECT messageID FROM posts_likes pl  WHERE pl.userID = ? AND pl.like=true)  THEN 1
 				      WHEN p.id IN (SELECT messageID FROM posts_likes pl  WHERE pl.userID = ? AND pl.like=false) THEN 0
@@ -67,14 +73,14 @@ func (f *ForumModel) GetPostByID(id int, userID int) (*model.Post, error) {
 			  LEFT JOIN post_categories pc ON pc.postID=p.id
 			  LEFT JOIN categories c ON c.id=pc.categoryID
 			  LEFT JOIN posts_likes pl ON pl.messageID=p.id 
-			  WHERE p.id = ?		 
+			  WHERE p.id = ? AND p.authorID NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID = ?)
 			  GROUP BY c.id;
 		`
 
 	// exequting the query
 	var rows *sql.Rows
 	var err error
-	rows, err = f.DB.Query(query, userID, userID, id)
+	rows, err = f.DB.Query(query, userID, userID, id, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -122,10 +128,10 @@ func (f *ForumModel) GetPostByID(id int, userID int) (*model.Post, error) {
 	    FROM comments c
 		LEFT JOIN users u ON u.id=c.authorID
 	    LEFT JOIN comments_likes cl ON cl.messageID=c.id 
-		WHERE c.postID = ?		 
+		WHERE c.postID = ? AND c.authorID NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID = ?)
 		GROUP BY c.id;
 		`
-	rows, err = f.DB.Query(query, userID, userID, id)
+	rows, err = f.DB.Query(query, userID, userID, id, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, model.ErrNoRecord
@@ -200,7 +206,7 @@ func rowScanForPostByID(rows *sql.Rows) (*model.Post, *model.Category, error) {
 	var images sql.NullString
 
 	// parse the row with fields:
-	// p.id, p.theme, p.content, p.images, p.authorID, u.name, u.dateCreate, c.id, c.name,  p.dateCreate,
+	// p.id, p.theme, p.content, p.images, p.authorID, u.name, u.dateCreate, c.id, c.name,  p.dateCreate, p.locked,
 	// count(CASE WHEN pl.like THEN TRUE END), count(CASE WHEN NOT pl.like THEN TRUE END)
 	// (CASE WHEN p.id IN (SELECT messageID FROM posts_likes pl  WHERE pl.userID = ? AND pl.like=true)  THEN 1
 	// 			      WHEN p.id IN (SELECT messageID FROM posts_likes pl  WHERE pl.userID = ? AND pl.like=false) THEN 0
@@ -209,7 +215,7 @@ func rowScanForPostByID(rows *sql.Rows) (*model.Post, *model.Category, error) {
 		&post.Message.Content, &images,
 		&post.Message.Author.ID, &post.Message.Author.Name, &post.Message.Author.DateCreate,
 		&category.ID, &category.Name,
-		&post.Message.DateCreate,
+		&post.Message.DateCreate, &post.Locked,
 		&post.Message.Likes[model.LIKE], &post.Message.Likes[model.DISLIKE],
 		&post.Message.UserReaction,
 	)
@@ -222,7 +228,7 @@ func rowScanForPostByID(rows *sql.Rows) (*model.Post, *model.Category, error) {
 /*
 returns 'postNumbers' posts with ids less than 'beforeId' and matching the filter.
 The posts are sorted by date of creation in descending order.
-The parametr userID is used to mark the user's reaction to a post.
+The parametr userID is used to mark the user's reaction to a post, the posts of the users blocked by the user are skipped.
 If beforeId and/or postNumbers is less or equal to 0 it will ignore the conditions.
 */
 func (f *ForumModel) GetPosts(beforeId int, postNumbers int, filter *model.Filter, userIDForReaction int) ([]*model.Post, error) {
@@ -275,6 +281,17 @@ func (f *ForumModel) GetPosts(beforeId int, postNumbers int, filter *model.Filte
 		arguments = append(arguments, beforeId)
 	}
 
+	if userIDForReaction > 0 {
+		// the posts of the users blocked by the user are hidden
+		if condition == "" {
+			condition = `WHERE `
+		} else {
+			condition += " AND "
+		}
+		condition += `p.authorID NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID = ?)`
+		arguments = append(arguments, userIDForReaction)
+	}
+
 	return f.getPostsByCondition(postNumbers, condition, arguments, userIDForReaction)
 }
 
@@ -313,7 +330,8 @@ addes the condition to a query and run it. Returnes found posts
 */
 func (f *ForumModel) getPostsByCondition(postNumbers int, condition string, argumentsForCondition []any, userID int) ([]*model.Post, error) {
 	query := `SELECT p.id, p.theme, p.content, p.images, p.authorID, u.name, u.dateCreate, c.id, c.name,  p.dateCreate, 
-				(SELECT count(id) FROM comments cm WHERE cm.postID=p.id),
+				(SELECT count(id) FROM comments cm WHERE cm.postID=p.id
+					AND cm.authorID NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID = ?)),
 				count(CASE WHEN pl.like THEN TRUE END), count(CASE WHEN NOT pl.like THEN TRUE END),
 				(CASE WHEN ul.like is NULL THEN -1 WHEN ul.like THEN 1 WHEN NOT  ul.like THEN 0 END)
 				
@@ -330,7 +348,7 @@ func (f *ForumModel) getPostsByCondition(postNumbers int, condition string, argu
 	// exequting the query
 	var rows *sql.Rows
 	var err error
-	rows, err = f.DB.Query(query, append([]any{userID}, argumentsForCondition...)...)
+	rows, err = f.DB.Query(query, append([]any{userID, userID}, argumentsForCondition...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -428,6 +446,10 @@ func addNewPostStruct(posts *[]*model.Post, post *model.Post, category *model.Ca
 modify a post with the given id
 */
 func (f *ForumModel) ModifyPost(id int, theme, content string, images []string) error {
+	return f.modifyPost(f.DB, id, theme, content, images)
+}
+
+func (f *ForumModel) modifyPost(db executor, id int, theme, content string, images []string) error {
 	fields := ""
 	fieldsValues := []any{}
 	if theme != "" {
@@ -449,10 +471,106 @@ func (f *ForumModel) ModifyPost(id int, theme, content string, images []string)
 	fieldsValues = append(fieldsValues, id)
 
 	q := fmt.Sprintf("UPDATE posts SET %s WHERE id=?", fields)
-	_, err := f.DB.Exec(q, fieldsValues...)
+	_, err := db.Exec(q, fieldsValues...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return f.updateSearchIndex(db, model.SEARCH_POST, id)
+}
+
+/*
+saves the current version of the post to the edit history and changes the post's theme and content.
+Returns ErrNoRecord if there is no such post
+*/
+func (f *ForumModel) EditPost(id int, theme, content string, dateEdit time.Time) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = f.saveToEditHistory(tx, model.POST, id, dateEdit)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.modifyPost(tx, id, theme, content, nil)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+deletes the post with its comments, likes and edit history.
+Returns ErrNoRecord if there is no such post
+*/
+func (f *ForumModel) DeletePost(id int) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	commentsID, err := f.getPostCommentsID(tx, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	for _, commentID := range commentsID {
+		err = f.deleteComment(tx, commentID)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	err = f.deleteLikeByMessageID(tx, model.POST+"s_likes", id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM posts WHERE id=?`, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.checkUnique(res)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	err = f.deleteEditHistory(tx, model.POST, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	err = f.deleteFromSearchIndex(tx, model.SEARCH_POST, id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+returns ids of all the comments of the post
+*/
+func (f *ForumModel) getPostCommentsID(db executor, postID int) ([]int, error) {
+	rows, err := db.Query(`SELECT id FROM comments WHERE postID=?`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commentsID []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		commentsID = append(commentsID, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return commentsID, nil
 }
@@ -1,6 +1,7 @@
 package sqlpkg
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -387,3 +388,95 @@ func TestGetPostByDI(t *testing.T) {
 	}
 	fmt.Printf("%s\n", post.String())
 }
+
+func TestEditAndDeletePost(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	f := ForumModel{db}
+
+	date := time.Date(2023, time.October, 7, 12, 12, 21, 0, time.UTC)
+	postID, err := f.InsertPost("theme to edit", "content to edit", nil, 1, date, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commentID, err := f.InsertComment(postID, "comment to edit", nil, 2, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.InsertCommentLike(1, commentID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.InsertPostLike(2, postID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.EditPost(postID, "edited theme", "edited content", date.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.EditComment(commentID, "edited comment", date.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := f.GetPostByID(postID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Theme != "edited theme" || post.Message.Content != "edited content" {
+		t.Errorf("post is not edited: theme '%s', content '%s'", post.Theme, post.Message.Content)
+	}
+	history, err := f.GetEditHistory(model.POST, postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Theme != "theme to edit" || history[0].Content != "content to edit" {
+		t.Errorf("wrong edit history of the post: %v", history)
+	}
+	history, err = f.GetEditHistory(model.COMMENT, commentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Theme != "" || history[0].Content != "comment to edit" {
+		t.Errorf("wrong edit history of the comment: %v", history)
+	}
+
+	err = f.EditPost(-1, "theme", "content", date)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("editing of a missing post: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	err = f.DeletePost(postID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.GetPostByID(postID, 0)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("the post is not deleted: got error %v", err)
+	}
+	_, err = f.GetCommentByID(commentID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("the comment of the post is not deleted: got error %v", err)
+	}
+	for _, table := range []string{"posts_likes", "comments_likes", "edit_history"} {
+		var n int
+		err = f.DB.QueryRow(`SELECT count(*) FROM `+table+` WHERE messageID IN (?, ?)`, postID, commentID).Scan(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%d records of the deleted post are left in %s", n, table)
+		}
+	}
+
+	err = f.DeletePost(postID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("deleting of a deleted post: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
@@ -0,0 +1,48 @@
+package sqlpkg
+
+import (
+	"forum/model"
+)
+
+/*
+returns the numbers of posts and comments written by the user
+*/
+func (f *ForumModel) GetUserMessagesCount(userID int) (postsCount, commentsCount int, err error) {
+	q := `SELECT (SELECT count(*) FROM posts WHERE authorID=?), (SELECT count(*) FROM comments WHERE authorID=?)`
+	err = f.DB.QueryRow(q, userID, userID).Scan(&postsCount, &commentsCount)
+	return postsCount, commentsCount, err
+}
+
+/*
+returns 'n' latest posts and comments of the user, the latest ones are the first
+*/
+func (f *ForumModel) GetUserRecentActivity(userID, n int) ([]*model.Activity, error) {
+	q := `SELECT ? AS type, p.id, p.id, p.theme, p.content, p.dateCreate FROM posts p WHERE p.authorID=?
+		UNION ALL
+		SELECT ?, c.id, c.postID, p.theme, c.content, c.dateCreate FROM comments c
+		INNER JOIN posts p ON p.id=c.postID
+		WHERE c.authorID=?
+		ORDER BY 6 DESC, 2 DESC
+		LIMIT ?`
+	rows, err := f.DB.Query(q, model.POST, userID, model.COMMENT, userID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*model.Activity{}
+	for rows.Next() {
+		activity := &model.Activity{}
+		err := rows.Scan(&activity.Type, &activity.ID, &activity.PostID, &activity.Theme, &activity.Content, &activity.DateCreate)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
@@ -0,0 +1,56 @@
+package sqlpkg
+
+import (
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestGetUserRecentActivity(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	postsBefore, commentsBefore, err := f.GetUserMessagesCount(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	postID, err := f.InsertPost("theme of the profile", "post of the profile", nil, userID, now, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeletePost(postID)
+	commentID, err := f.InsertComment(postID, "comment of the profile", nil, userID, now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postsCount, commentsCount, err := f.GetUserMessagesCount(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postsCount != postsBefore+1 || commentsCount != commentsBefore+1 {
+		t.Errorf("got %d posts and %d comments, want %d and %d", postsCount, commentsCount, postsBefore+1, commentsBefore+1)
+	}
+
+	activities, err := f.GetUserRecentActivity(userID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activities) != 2 {
+		t.Fatalf("got %d activities, want 2", len(activities))
+	}
+	if activities[0].Type != model.COMMENT || activities[0].ID != commentID || activities[0].PostID != postID || activities[0].Theme != "theme of the profile" {
+		t.Errorf("the latest activity is %+v, want the comment %d", activities[0], commentID)
+	}
+	if activities[1].Type != model.POST || activities[1].ID != postID || activities[1].DateCreate.IsZero() {
+		t.Errorf("the second activity is %+v, want the post %d", activities[1], postID)
+	}
+}
@@ -0,0 +1,152 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"forum/model"
+)
+
+/*
+adds a report of the user about a post, a comment or a chat message, returns the id of the report.
+Returns ErrUnique if the user has an open or claimed report about the same item already
+*/
+func (f *ForumModel) InsertReport(reporterID int, targetType string, targetID int, reason string, dateCreate time.Time) (int, error) {
+	q := `INSERT INTO reports (reporterID, targetType, targetID, reason, dateCreate)
+		  SELECT ?,?,?,?,?
+		  WHERE NOT EXISTS (SELECT 1 FROM reports
+				WHERE reporterID=? AND targetType=? AND targetID=? AND status IN ('open', 'claimed'))`
+	res, err := f.DB.Exec(q, reporterID, targetType, targetID, reason, dateCreate, reporterID, targetType, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, model.ErrUnique
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+const reportFields = `r.id, r.reporterID, ru.name, r.targetType, r.targetID, r.reason, r.status, r.moderatorID, mu.name, r.dateCreate, r.dateUpdate
+		  FROM reports r
+		  LEFT JOIN users ru ON ru.id=r.reporterID
+		  LEFT JOIN users mu ON mu.id=r.moderatorID`
+
+/*
+returns the report with the given id
+*/
+func (f *ForumModel) GetReportByID(id int) (*model.Report, error) {
+	q := `SELECT ` + reportFields + ` WHERE r.id=?`
+	rows, err := f.DB.Query(q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports, err := scanReports(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, model.ErrNoRecord
+	}
+	return reports[0], nil
+}
+
+/*
+returns 'n' reports with one of the statuses and ids less than 'beforeID', the latest reports are the first.
+If beforeID is less or equal to 0 it will return the latest reports
+*/
+func (f *ForumModel) GetReports(statuses []string, beforeID, n int) ([]*model.Report, error) {
+	if len(statuses) == 0 {
+		return []*model.Report{}, nil
+	}
+
+	q := `SELECT ` + reportFields + `
+		  WHERE r.status IN (?` + strings.Repeat(",?", len(statuses)-1) + `) AND (?<=0 OR r.id<?)
+		  ORDER BY r.id DESC
+		  LIMIT ?`
+	arguments := make([]any, 0, len(statuses)+3)
+	for _, status := range statuses {
+		arguments = append(arguments, status)
+	}
+	arguments = append(arguments, beforeID, beforeID, n)
+
+	rows, err := f.DB.Query(q, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReports(rows)
+}
+
+/*
+assigns the open report to the moderator. Returns ErrNoRecord if there is no such open report
+*/
+func (f *ForumModel) ClaimReport(id, moderatorID int, dateUpdate time.Time) error {
+	q := `UPDATE reports SET status='claimed', moderatorID=?, dateUpdate=? WHERE id=? AND status='open'`
+	res, err := f.DB.Exec(q, moderatorID, dateUpdate, id)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+closes the report with the status REPORT_RESOLVED or REPORT_DISMISSED.
+Only open reports or reports claimed by the same moderator can be closed, otherwise it returns ErrNoRecord
+*/
+func (f *ForumModel) CloseReport(id, moderatorID int, status string, dateUpdate time.Time) error {
+	if status != model.REPORT_RESOLVED && status != model.REPORT_DISMISSED {
+		return errors.New("invalid status for closing a report: " + status)
+	}
+
+	q := `UPDATE reports SET status=?, moderatorID=?, dateUpdate=?
+		  WHERE id=? AND (status='open' OR (status='claimed' AND moderatorID=?))`
+	res, err := f.DB.Exec(q, status, moderatorID, dateUpdate, id, moderatorID)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+func scanReports(rows *sql.Rows) ([]*model.Report, error) {
+	reports := []*model.Report{}
+	for rows.Next() {
+		report := &model.Report{}
+		var reporterID, moderatorID sql.NullInt64
+		var reporterName, moderatorName sql.NullString
+		var dateUpdate sql.NullTime
+		err := rows.Scan(&report.ID, &reporterID, &reporterName, &report.TargetType, &report.TargetID, &report.Reason, &report.Status,
+			&moderatorID, &moderatorName, &report.DateCreate, &dateUpdate)
+		if err != nil {
+			return nil, err
+		}
+		if reporterID.Valid {
+			report.Reporter = &model.User{ID: int(reporterID.Int64), Name: reporterName.String}
+		}
+		if moderatorID.Valid {
+			report.Moderator = &model.User{ID: int(moderatorID.Int64), Name: moderatorName.String}
+		}
+		report.DateUpdate = dateUpdate.Time
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
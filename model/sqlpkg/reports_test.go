@@ -0,0 +1,88 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestReports(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const reporterID, moderatorID, otherModeratorID = 3, 1, 2
+	now := time.Now()
+
+	postID, err := f.InsertPost("theme to report", "content to report", nil, reporterID, now, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeletePost(postID)
+	defer db.Exec(`DELETE FROM reports WHERE targetType=? AND targetID=?`, model.POST, postID)
+
+	id, err := f.InsertReport(reporterID, model.POST, postID, "spam", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.InsertReport(reporterID, model.POST, postID, "spam again", now)
+	if !errors.Is(err, model.ErrUnique) {
+		t.Errorf("reporting the same post twice: got error %v, want %v", err, model.ErrUnique)
+	}
+
+	report, err := f.GetReportByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != model.REPORT_OPEN || report.Reporter == nil || report.Reporter.ID != reporterID ||
+		report.TargetType != model.POST || report.TargetID != postID || report.Reason != "spam" || report.Moderator != nil {
+		t.Errorf("wrong report: %+v", report)
+	}
+
+	reports, err := f.GetReports([]string{model.REPORT_OPEN}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].ID != id {
+		t.Errorf("the report %d is not the latest open report: %v", id, reports)
+	}
+
+	err = f.ClaimReport(id, moderatorID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.ClaimReport(id, otherModeratorID, now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("claiming a claimed report: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	err = f.CloseReport(id, otherModeratorID, model.REPORT_DISMISSED, now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("closing a report claimed by another moderator: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	err = f.CloseReport(id, moderatorID, model.REPORT_OPEN, now)
+	if err == nil {
+		t.Errorf("closing a report with the status '%s' is accepted", model.REPORT_OPEN)
+	}
+	err = f.CloseReport(id, moderatorID, model.REPORT_RESOLVED, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = f.GetReportByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != model.REPORT_RESOLVED || report.Moderator == nil || report.Moderator.ID != moderatorID {
+		t.Errorf("wrong resolved report: %+v", report)
+	}
+
+	_, err = f.InsertReport(reporterID, model.POST, postID, "spam again", now)
+	if err != nil {
+		t.Errorf("reporting the post after the report is resolved: %v", err)
+	}
+}
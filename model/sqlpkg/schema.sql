@@ -7,7 +7,12 @@
 			dateBirth TIMESTAMP NOT NULL,
 			gender TEXT NOT NULL,    
 			firstName TEXT NOT NULL,
-			lastName TEXT NOT NULL
+			lastName TEXT NOT NULL,
+			role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin')),
+			dateBan TIMESTAMP,
+			suspendedUntil TIMESTAMP,
+			mutedUntil TIMESTAMP,
+			verified BOOL NOT NULL DEFAULT FALSE
 		);
 
 		CREATE TABLE IF NOT EXISTS 'usersessions' (
@@ -16,8 +21,92 @@
 			uuid TEXT NOT NULL UNIQUE,
 			expirySession TIMESTAMP NOT NULL,
 			agent TEXT,
+			ip TEXT,
+			dateCreate TIMESTAMP,
+			lastSeen TIMESTAMP,
+			remember BOOL NOT NULL DEFAULT FALSE,
 			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
 		);
+
+		CREATE TABLE IF NOT EXISTS 'password_resets' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			userID INT NOT NULL,
+			tokenHash TEXT NOT NULL UNIQUE,
+			dateCreate TIMESTAMP NOT NULL,
+			dateExpire TIMESTAMP NOT NULL,
+			dateUse TIMESTAMP,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS password_resets_user_index ON password_resets(userID, dateCreate);
+
+		CREATE TABLE IF NOT EXISTS 'email_verifications' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			userID INT NOT NULL,
+			email TEXT NOT NULL,
+			tokenHash TEXT NOT NULL UNIQUE,
+			dateCreate TIMESTAMP NOT NULL,
+			dateExpire TIMESTAMP NOT NULL,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS email_verifications_user_index ON email_verifications(userID, dateCreate);
+
+		CREATE TABLE IF NOT EXISTS 'login_failures' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			userID INT,
+			ip TEXT NOT NULL,
+			dateCreate TIMESTAMP NOT NULL,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS login_failures_user_index ON login_failures(userID, dateCreate);
+		CREATE INDEX IF NOT EXISTS login_failures_ip_index ON login_failures(ip, dateCreate);
+
+		CREATE TABLE IF NOT EXISTS 'login_lockouts' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			userID INT,
+			ip TEXT NOT NULL,
+			failures INT NOT NULL,
+			dateCreate TIMESTAMP NOT NULL,
+			dateExpire TIMESTAMP NOT NULL,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE SET NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS 'user_totp' (
+			userID INTEGER PRIMARY KEY NOT NULL,
+			secret TEXT NOT NULL,
+			enabled BOOL NOT NULL DEFAULT FALSE,
+			lastStep INT NOT NULL DEFAULT 0,
+			dateCreate TIMESTAMP NOT NULL,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS 'totp_recovery_codes' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			userID INT NOT NULL,
+			codeHash TEXT NOT NULL,
+			dateUse TIMESTAMP,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS totp_recovery_codes_user_index ON totp_recovery_codes(userID, codeHash);
+
+		CREATE TABLE IF NOT EXISTS 'user_oauth_accounts' (
+			provider TEXT NOT NULL,
+			providerUserID TEXT NOT NULL,
+			userID INT NOT NULL,
+			dateCreate TIMESTAMP NOT NULL,
+			PRIMARY KEY (provider, providerUserID),
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS 'user_blocks' (
+			blockerID INT NOT NULL,
+			blockedID INT NOT NULL,
+			dateCreate TIMESTAMP NOT NULL,
+			PRIMARY KEY (blockerID, blockedID),
+			CHECK (blockerID != blockedID),
+			FOREIGN KEY (blockerID) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (blockedID) REFERENCES users(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS user_blocks_blocked_index ON user_blocks(blockedID);
 		
 		CREATE TABLE IF NOT EXISTS 'posts_likes' (
 			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
@@ -36,7 +125,7 @@
 			like BOOL NOT NULL,
 			UNIQUE (userID, messageID),
 			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE,
-			FOREIGN KEY (messageID) REFERENCES posts(id) ON DELETE CASCADE
+			FOREIGN KEY (messageID) REFERENCES comments(id) ON DELETE CASCADE
 		);
 
 		CREATE TABLE IF NOT EXISTS 'posts' (
@@ -46,6 +135,7 @@
 			images TEXT, 
 			authorID INT NOT NULL,
 			dateCreate TIMESTAMP NOT NULL,
+			locked BOOL NOT NULL DEFAULT FALSE,
 			FOREIGN KEY (authorID) REFERENCES users(id) ON DELETE CASCADE
 		);
 
@@ -60,6 +150,42 @@
 			FOREIGN KEY (postID) REFERENCES posts(id) ON DELETE CASCADE
 		);
 		
+		CREATE TABLE IF NOT EXISTS 'edit_history' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			messageType TEXT NOT NULL,
+			messageID INT NOT NULL,
+			theme TEXT,
+			content TEXT NOT NULL,
+			images TEXT,
+			dateEdit TIMESTAMP NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS 'moderation_log' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			moderatorID INT,
+			action TEXT NOT NULL,
+			targetType TEXT NOT NULL,
+			targetID INT NOT NULL,
+			reason TEXT,
+			date TIMESTAMP NOT NULL,
+			FOREIGN KEY (moderatorID) REFERENCES users(id) ON DELETE SET NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS 'reports' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			reporterID INT,
+			targetType TEXT NOT NULL CHECK (targetType IN ('post', 'comment', 'chatMessage')),
+			targetID INT NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'claimed', 'resolved', 'dismissed')),
+			moderatorID INT,
+			dateCreate TIMESTAMP NOT NULL,
+			dateUpdate TIMESTAMP,
+			FOREIGN KEY (reporterID) REFERENCES users(id) ON DELETE SET NULL,
+			FOREIGN KEY (moderatorID) REFERENCES users(id) ON DELETE SET NULL
+		);
+		CREATE INDEX IF NOT EXISTS reports_status_index ON reports(status, id);
+
 		CREATE TABLE IF NOT EXISTS 'categories' (
 			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 			name TEXT NOT NULL 
@@ -78,13 +204,18 @@
 			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 			name TEXT NOT NULL,
 			type INTEGER NOT NULL,
-			UNIQUE (name, type)
+			title TEXT,
+			creatorID INTEGER,
+			UNIQUE (name, type),
+			FOREIGN KEY (creatorID) REFERENCES users(id) ON DELETE SET NULL
 		);
 
 		CREATE TABLE IF NOT EXISTS 'chat_members' (
 			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 			chatID INTEGER NOT NULL,
 			userID INTEGER NOT NULL,
+			lastReadMessageID INTEGER NOT NULL DEFAULT 0,
+			active BOOL NOT NULL DEFAULT TRUE,
 			UNIQUE (chatID, userID),
 			FOREIGN KEY (chatID) REFERENCES chats(id) ON DELETE CASCADE,
 			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
@@ -96,10 +227,23 @@
 			images TEXT, 
 			chat_membersID INT NOT NULL,
 			dateCreate TIMESTAMP NOT NULL,
+			dateEdit TIMESTAMP,
+			dateDelete TIMESTAMP,
 			FOREIGN KEY (chat_membersID) REFERENCES chat_members(id) ON DELETE CASCADE
 		);
+
+		CREATE TABLE IF NOT EXISTS 'chat_messages_receipts' (
+			id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+			messageID INTEGER NOT NULL,
+			userID INTEGER NOT NULL,
+			dateDelivered TIMESTAMP,
+			dateRead TIMESTAMP,
+			UNIQUE (messageID, userID),
+			FOREIGN KEY (messageID) REFERENCES chat_messages(id) ON DELETE CASCADE,
+			FOREIGN KEY (userID) REFERENCES users(id) ON DELETE CASCADE
+		);
 		
-		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName) VALUES (?,?,?,?,?,?,?,?);
+		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName, role, verified) VALUES (?,?,?,?,?,?,?,?,'admin',TRUE);
 		INSERT INTO categories (name) VALUES (?), (?), (?), (?);
 		
         SELECT auth_user_add('webuser', 'webuser', 0);
\ No newline at end of file
@@ -0,0 +1,52 @@
+package sqlpkg
+
+import (
+	"html"
+	"strings"
+)
+
+// The full-text search needs the FTS5 extension of SQLite, which is compiled in go-sqlite3
+// only with the 'sqlite_fts5' build tag: go run -tags sqlite_fts5 .
+// Without the tag the index is not kept and Search returns model.ErrNoSearch.
+
+// marks around the found words in the snippets of search results, the rest of the snippets is HTML-escaped
+const (
+	SEARCH_HIGHLIGHT_START = "<mark>"
+	SEARCH_HIGHLIGHT_END   = "</mark>"
+)
+
+// the marks put by the search index, they are not HTML, so the content can be escaped before the HTML marks are put
+const (
+	searchMarkStart = "\x02"
+	searchMarkEnd   = "\x03"
+)
+
+var searchMarksReplacer = strings.NewReplacer(searchMarkStart, SEARCH_HIGHLIGHT_START, searchMarkEnd, SEARCH_HIGHLIGHT_END)
+
+const searchKindsNumber = 4 // model.SEARCH_CHAT_MESSAGE + 1
+
+/*
+returns the rowid of the item in the search index, the rowid is unique for each kind and id of the items
+*/
+func searchRowID(kind, id int) int {
+	return id*searchKindsNumber + kind
+}
+
+/*
+escapes the text found by the search index, so it is safe to show it as HTML, and highlights the found words
+*/
+func highlightSearchText(text string) string {
+	return searchMarksReplacer.Replace(html.EscapeString(text))
+}
+
+/*
+converts the text typed by a user to a full-text query, which finds the items containing all the words of the text.
+Each word is quoted, so the characters of the query syntax are searched as usual characters
+*/
+func createSearchQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
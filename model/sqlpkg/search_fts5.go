@@ -0,0 +1,158 @@
+//go:build sqlite_fts5 || fts5
+
+package sqlpkg
+
+import (
+	"database/sql"
+	"fmt"
+
+	"forum/model"
+)
+
+/*
+selections of the indexed fields for each kind of items, in the order of the search_index columns.
+The condition has to be added to the WHERE clause
+*/
+var searchSources = map[int]string{
+	model.SEARCH_POST:         `SELECT id*?+?, ?, id, theme, content FROM posts WHERE %s`,
+	model.SEARCH_COMMENT:      `SELECT id*?+?, ?, id, '', content FROM comments WHERE %s`,
+	model.SEARCH_CHAT_MESSAGE: `SELECT id*?+?, ?, id, '', content FROM chat_messages WHERE dateDelete IS NULL AND %s`,
+}
+
+/*
+creates the search index and fills it in with the existing posts, comments and chat messages,
+if the DB has the forum's tables but doesn't have the index yet
+*/
+func initSearchIndex(db *sql.DB) error {
+	var postsExist, indexExists bool
+	q := `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type='table' AND name='posts'),
+				 EXISTS (SELECT 1 FROM sqlite_master WHERE name='search_index')`
+	err := db.QueryRow(q).Scan(&postsExist, &indexExists)
+	if err != nil {
+		return err
+	}
+	if !postsExist || indexExists {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	q = `CREATE VIRTUAL TABLE search_index USING fts5(kind UNINDEXED, itemID UNINDEXED, title, content, tokenize='unicode61 remove_diacritics 2')`
+	_, err = tx.Exec(q)
+	if err != nil {
+		return rollback(tx, fmt.Errorf("creating the search index failed: %w", err))
+	}
+
+	for kind, source := range searchSources {
+		q = `INSERT INTO search_index (rowid, kind, itemID, title, content) ` + fmt.Sprintf(source, "TRUE")
+		_, err = tx.Exec(q, searchKindsNumber, kind, kind)
+		if err != nil {
+			return rollback(tx, fmt.Errorf("filling in the search index failed: %w", err))
+		}
+	}
+
+	return tx.Commit()
+}
+
+/*
+puts the current content of the item to the search index.
+If the item doesn't exist (or it is a deleted chat message), the item is removed from the index
+*/
+func (f *ForumModel) updateSearchIndex(db executor, kind, id int) error {
+	err := f.deleteFromSearchIndex(db, kind, id)
+	if err != nil {
+		return err
+	}
+
+	q := `INSERT INTO search_index (rowid, kind, itemID, title, content) ` + fmt.Sprintf(searchSources[kind], "id=?")
+	_, err = db.Exec(q, searchKindsNumber, kind, kind, id)
+	if err != nil {
+		return fmt.Errorf("updating the search index failed: %w", err)
+	}
+	return nil
+}
+
+func (f *ForumModel) deleteFromSearchIndex(db executor, kind, id int) error {
+	q := `DELETE FROM search_index WHERE rowid=?`
+	_, err := db.Exec(q, searchRowID(kind, id))
+	if err != nil {
+		return fmt.Errorf("deleting from the search index failed: %w", err)
+	}
+	return nil
+}
+
+/*
+returns maximum 'resultsNumber' posts, comments and messages of the user's chats, which contain all the words of the 'text'.
+The items of the authors blocked by the user are skipped.
+The results are sorted by relevance, the words in the posts' themes weigh more than in the content.
+Only results after the cursor 'after' are returned, the zero cursor means the most relevant results.
+If resultsNumber is less or equal to 0 it will ignore the condition.
+*/
+func (f *ForumModel) Search(text string, userID int, after model.SearchCursor, resultsNumber int) ([]*model.SearchResult, error) {
+	q := `SELECT s.rowid, s.kind, s.itemID, s.title, s.snippet, s.score, ifnull(c.postID, 0), ifnull(cm.chatID, 0),
+			u.id, u.name, p.dateCreate, c.dateCreate, ms.dateCreate
+		FROM (SELECT rowid, kind, itemID,
+					highlight(search_index, 2, ?, ?) AS title,
+					snippet(search_index, 3, ?, ?, '...', 16) AS snippet,
+					bm25(search_index, 0.0, 0.0, 2.0, 1.0) AS score
+				FROM search_index WHERE search_index MATCH ?) s
+		LEFT JOIN posts p ON s.kind=? AND p.id=s.itemID
+		LEFT JOIN comments c ON s.kind=? AND c.id=s.itemID
+		LEFT JOIN chat_messages ms ON s.kind=? AND ms.id=s.itemID
+		LEFT JOIN chat_members cm ON cm.id=ms.chat_membersID
+		LEFT JOIN users u ON u.id=coalesce(p.authorID, c.authorID, cm.userID)
+		WHERE (s.kind!=? OR cm.chatID IN (SELECT chatID FROM chat_members WHERE userID=? AND active))
+		AND (u.id IS NULL OR u.id NOT IN (SELECT blockedID FROM user_blocks WHERE blockerID=?))
+		AND (? OR s.score>? OR (s.score=? AND s.rowid>?))
+		ORDER BY s.score, s.rowid`
+	arguments := []any{
+		searchMarkStart, searchMarkEnd, searchMarkStart, searchMarkEnd, createSearchQuery(text),
+		model.SEARCH_POST, model.SEARCH_COMMENT, model.SEARCH_CHAT_MESSAGE,
+		model.SEARCH_CHAT_MESSAGE, userID,
+		userID,
+		after.RowID == 0, after.Rank, after.Rank, after.RowID,
+	}
+	if resultsNumber > 0 {
+		q += ` LIMIT ?`
+		arguments = append(arguments, resultsNumber)
+	}
+
+	rows, err := f.DB.Query(q, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*model.SearchResult{}
+	for rows.Next() {
+		result := &model.SearchResult{}
+		var authorID sql.NullInt64
+		var authorName sql.NullString
+		var postDate, commentDate, messageDate sql.NullTime
+		err := rows.Scan(&result.Cursor.RowID, &result.Kind, &result.ID, &result.Title, &result.Snippet, &result.Cursor.Rank,
+			&result.PostID, &result.ChatID, &authorID, &authorName, &postDate, &commentDate, &messageDate)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Title, result.Snippet = highlightSearchText(result.Title), highlightSearchText(result.Snippet)
+		if authorID.Valid {
+			result.Author = &model.User{ID: int(authorID.Int64), Name: authorName.String}
+		}
+		for _, date := range []sql.NullTime{postDate, commentDate, messageDate} {
+			if date.Valid {
+				result.DateCreate = date.Time
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,31 @@
+//go:build !(sqlite_fts5 || fts5)
+
+package sqlpkg
+
+import (
+	"database/sql"
+
+	"forum/model"
+)
+
+/*
+the search index is not used without FTS5
+*/
+func initSearchIndex(db *sql.DB) error {
+	return nil
+}
+
+func (f *ForumModel) updateSearchIndex(db executor, kind, id int) error {
+	return nil
+}
+
+func (f *ForumModel) deleteFromSearchIndex(db executor, kind, id int) error {
+	return nil
+}
+
+/*
+returns model.ErrNoSearch, the full-text search is not available without FTS5
+*/
+func (f *ForumModel) Search(text string, userID int, after model.SearchCursor, resultsNumber int) ([]*model.SearchResult, error) {
+	return nil, model.ErrNoSearch
+}
@@ -0,0 +1,137 @@
+//go:build sqlite_fts5 || fts5
+
+package sqlpkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestSearch(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	postID, err := f.InsertPost("Zanzibar travel", "Spice farms and beaches", nil, 4, time.Now(), []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commentID, err := f.InsertComment(postID, "I loved the zanzibar beaches", nil, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Delete(commentID)
+
+	chatID, _, err := f.GetPrivateChat(4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageID, err := f.InsertChatMessage(chatID, 4, "let's go to Zanzibar", nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DeleteChatMessage(messageID)
+
+	results, err := f.Search("zanzibar", 4, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d: %#v", len(results), results)
+	}
+	// the word in the post's theme weighs more
+	if results[0].Kind != model.SEARCH_POST || results[0].ID != postID || !strings.Contains(results[0].Title, SEARCH_HIGHLIGHT_START) {
+		t.Fatalf("expected the post %d to be the first with the highlighted title, got %#v", postID, results[0])
+	}
+
+	// the chat message is found by the chat members only
+	results, err = f.Search("zanzibar", 1, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Kind == model.SEARCH_CHAT_MESSAGE && r.ID == messageID {
+			t.Fatalf("the chat message %d is found by a user who is not a member of the chat", messageID)
+		}
+	}
+
+	// pagination
+	first, err := f.Search("zanzibar beaches", 4, model.SearchCursor{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := f.Search("zanzibar beaches", 4, first[0].Cursor, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || len(next) != 1 || first[0].Cursor == next[0].Cursor {
+		t.Fatalf("expected two different portions, got %#v and %#v", first, next)
+	}
+
+	// the index follows the changes
+	err = f.ModifyComment(commentID, "the beaches were fine", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.MarkChatMessageDeleted(messageID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the characters of the query syntax are not special
+	_, err = f.Search(`zanzibar" OR (beaches*`, 4, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err = f.Search("zanzibar", 4, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != postID {
+		t.Fatalf("expected only the post %d, got %#v", postID, results)
+	}
+
+	// the found text is HTML-escaped
+	htmlCommentID, err := f.InsertComment(postID, "<img src=x onerror=alert(1)> zanzibar", nil, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Delete(htmlCommentID)
+	results, err = f.Search("zanzibar", 4, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, r := range results {
+		if r.Kind == model.SEARCH_COMMENT && r.ID == htmlCommentID {
+			found = true
+			if strings.Contains(r.Snippet, "<img") || !strings.Contains(r.Snippet, "&lt;img") || !strings.Contains(r.Snippet, SEARCH_HIGHLIGHT_START) {
+				t.Errorf("expected the escaped snippet with the highlighted word, got %s", r.Snippet)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("the comment %d is not found", htmlCommentID)
+	}
+
+	// the items of the blocked authors are skipped
+	err = f.BlockUser(4, 5, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnblockUser(4, 5)
+	results, err = f.Search("zanzibar", 4, model.SearchCursor{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Author != nil && r.Author.ID == 5 {
+			t.Errorf("found the item %d of the blocked author", r.ID)
+		}
+	}
+}
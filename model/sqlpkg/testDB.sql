@@ -1,5 +1,5 @@
-		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName) VALUES ("test1","test1@forum", ? ,"2023-03-20 09:41:04.656479916+00:00", "2000-01-01 09:41:04.656479916+00:00","male", "Santa", "Claus");
-		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName) VALUES ("test2","test2@forum", ? ,"2023-03-20 09:52:07.656479916+00:00", "2000-01-02 09:41:04.656479916+00:00","female", "Mrs", "Claus");
+		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName, verified) VALUES ("test1","test1@forum", ? ,"2023-03-20 09:41:04.656479916+00:00", "2000-01-01 09:41:04.656479916+00:00","male", "Santa", "Claus", TRUE);
+		INSERT INTO users (name,email,password, dateCreate, dateBirth, gender, firstName, lastName, verified) VALUES ("test2","test2@forum", ? ,"2023-03-20 09:52:07.656479916+00:00", "2000-01-02 09:41:04.656479916+00:00","female", "Mrs", "Claus", TRUE);
 		
 		INSERT INTO posts (theme,content,authorID, dateCreate) VALUES ("cats", "cats are cute", 1, "2023-03-20 15:41:42.656479916+00:00");
 		INSERT INTO posts (theme,content,authorID, dateCreate) VALUES ("dogs", "dogs are funny", 2, "2023-03-21 14:41:04.656479916+00:00");
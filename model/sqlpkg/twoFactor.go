@@ -0,0 +1,147 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+returns the second factor of the user. Returns ErrNoRecord if the user has not set it up
+*/
+func (f *ForumModel) GetTwoFactor(userID int) (*model.TwoFactor, error) {
+	twoFactor := &model.TwoFactor{}
+	q := `SELECT secret, enabled, lastStep FROM user_totp WHERE userID=?`
+	err := f.DB.QueryRow(q, userID).Scan(&twoFactor.Secret, &twoFactor.Enabled, &twoFactor.LastStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrNoRecord
+	}
+	if err != nil {
+		return nil, err
+	}
+	return twoFactor, nil
+}
+
+/*
+keeps the new not enabled secret of the user, it replaces the secret, which was not enabled yet.
+Returns ErrUnique if the second factor of the user is enabled already
+*/
+func (f *ForumModel) SetTwoFactorSecret(userID int, secret string, dateCreate time.Time) error {
+	q := `INSERT INTO user_totp (userID, secret, dateCreate) VALUES (?,?,?)
+		  ON CONFLICT (userID) DO UPDATE SET secret=excluded.secret, lastStep=0, dateCreate=excluded.dateCreate
+		  WHERE enabled=FALSE`
+	res, err := f.DB.Exec(q, userID, secret, dateCreate)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUnique
+	}
+	return nil
+}
+
+/*
+enables the second factor of the user, the step of the code, which confirmed it, is used already.
+The recovery codes replace the old ones, only the hashes of the codes are kept in DB
+*/
+func (f *ForumModel) EnableTwoFactor(userID int, step int64, codeHashes []string) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	q := `UPDATE user_totp SET enabled=TRUE, lastStep=? WHERE userID=? AND enabled=FALSE`
+	res, err := tx.Exec(q, step, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if n == 0 {
+		return rollback(tx, model.ErrNoRecord)
+	}
+
+	q = `DELETE FROM totp_recovery_codes WHERE userID=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	q = `INSERT INTO totp_recovery_codes (userID, codeHash) VALUES (?,?)`
+	for _, codeHash := range codeHashes {
+		_, err = tx.Exec(q, userID, codeHash)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+/*
+marks the step as used by the user, the codes of the step and the earlier ones cannot be used then.
+Returns ErrNoRecord if the step or a later one is used already
+*/
+func (f *ForumModel) UseTwoFactorStep(userID int, step int64) error {
+	q := `UPDATE user_totp SET lastStep=? WHERE userID=? AND enabled=TRUE AND lastStep<?`
+	res, err := f.DB.Exec(q, step, userID, step)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+marks the recovery code of the user as used. Returns ErrNoRecord if there is no such unused code
+*/
+func (f *ForumModel) UseRecoveryCode(userID int, codeHash string, dateUse time.Time) error {
+	q := `UPDATE totp_recovery_codes SET dateUse=? WHERE id=(
+			SELECT id FROM totp_recovery_codes WHERE userID=? AND codeHash=? AND dateUse IS NULL LIMIT 1
+		  )`
+	res, err := f.DB.Exec(q, dateUse, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	return f.checkUnique(res)
+}
+
+/*
+returns the number of the user's recovery codes, which are not used yet
+*/
+func (f *ForumModel) CountRecoveryCodes(userID int) (int, error) {
+	var count int
+	q := `SELECT count(*) FROM totp_recovery_codes WHERE userID=? AND dateUse IS NULL`
+	err := f.DB.QueryRow(q, userID).Scan(&count)
+	return count, err
+}
+
+/*
+deletes the second factor and the recovery codes of the user
+*/
+func (f *ForumModel) DisableTwoFactor(userID int) error {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	q := `DELETE FROM totp_recovery_codes WHERE userID=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	q = `DELETE FROM user_totp WHERE userID=?`
+	_, err = tx.Exec(q, userID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,86 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestTwoFactor(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	defer f.DisableTwoFactor(userID)
+	now := time.Now()
+
+	_, err = f.GetTwoFactor(userID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Fatalf("getting a not set up second factor: got error %v, want %v", err, model.ErrNoRecord)
+	}
+
+	err = f.SetTwoFactorSecret(userID, "first secret", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.SetTwoFactorSecret(userID, "second secret", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twoFactor, err := f.GetTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twoFactor.Secret != "second secret" || twoFactor.Enabled {
+		t.Errorf("got the second factor %+v, want the not enabled second secret", *twoFactor)
+	}
+
+	err = f.EnableTwoFactor(userID, 100, []string{"code 1", "code 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.SetTwoFactorSecret(userID, "third secret", now)
+	if !errors.Is(err, model.ErrUnique) {
+		t.Errorf("replacing an enabled secret: got error %v, want %v", err, model.ErrUnique)
+	}
+
+	err = f.UseTwoFactorStep(userID, 100)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using a used step: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	err = f.UseTwoFactorStep(userID, 101)
+	if err != nil {
+		t.Errorf("using a new step: %v", err)
+	}
+
+	err = f.UseRecoveryCode(userID, "code 1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.UseRecoveryCode(userID, "code 1", now)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("using a used recovery code: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	count, err := f.CountRecoveryCodes(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("got %d unused recovery codes, want 1", count)
+	}
+
+	err = f.DisableTwoFactor(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.GetTwoFactor(userID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("getting a disabled second factor: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
@@ -0,0 +1,77 @@
+package sqlpkg
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"forum/model"
+)
+
+/*
+returns the not expired sessions of the user, the recently used sessions go first
+*/
+func (f *ForumModel) GetUserSessions(userID int, now time.Time) ([]*model.UserSession, error) {
+	q := `SELECT id, uuid, agent, ip, dateCreate, lastSeen, expirySession, remember FROM usersessions
+		  WHERE userID=? AND expirySession>?
+		  ORDER BY lastSeen DESC, id DESC`
+	rows, err := f.DB.Query(q, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*model.UserSession{}
+	for rows.Next() {
+		session := &model.UserSession{}
+		// the sessions created before the devices were kept have no data of the device
+		var agent, ip sql.NullString
+		var dateCreate, lastSeen sql.NullTime
+		err = rows.Scan(&session.ID, &session.Uuid, &agent, &ip, &dateCreate, &lastSeen, &session.ExpirySession, &session.Remember)
+		if err != nil {
+			return nil, err
+		}
+		session.Agent, session.IP = agent.String, ip.String
+		session.DateCreate, session.LastSeen = dateCreate.Time, lastSeen.Time
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+/*
+sets the time the session was used last
+*/
+func (f *ForumModel) UpdateSessionLastSeen(uuid string, lastSeen time.Time) error {
+	q := `UPDATE usersessions SET lastSeen=? WHERE uuid=?`
+	_, err := f.DB.Exec(q, lastSeen, uuid)
+	return err
+}
+
+/*
+deletes the session of the user by the session's id and returns the uuid of the deleted session.
+Returns ErrNoRecord if the user has no such session
+*/
+func (f *ForumModel) DeleteUserSessionByID(userID, sessionID int) (string, error) {
+	tx, err := f.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	var uuid string
+	q := `SELECT uuid FROM usersessions WHERE id=? AND userID=?`
+	err = tx.QueryRow(q, sessionID, userID).Scan(&uuid)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = model.ErrNoRecord
+	}
+	if err != nil {
+		return "", rollback(tx, err)
+	}
+
+	q = `DELETE FROM usersessions WHERE id=?`
+	_, err = tx.Exec(q, sessionID)
+	if err != nil {
+		return "", rollback(tx, err)
+	}
+
+	return uuid, tx.Commit()
+}
@@ -0,0 +1,88 @@
+package sqlpkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"forum/model"
+)
+
+func TestUserSessions(t *testing.T) {
+	db, err := OpenDB(DBPath, "webuser", "webuser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	f := ForumModel{db}
+
+	const userID = 2
+	now := time.Now()
+	err = f.AddUsersSession(userID, &model.UserSession{Uuid: "sessions-test-phone", ExpirySession: now.Add(time.Hour), Agent: "phone agent", IP: "10.0.0.1", DateCreate: now, LastSeen: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`DELETE FROM usersessions WHERE uuid=?`, "sessions-test-phone")
+	err = f.AddUsersSession(userID, &model.UserSession{Uuid: "sessions-test-laptop", ExpirySession: now.Add(time.Hour), Agent: "laptop agent", IP: "10.0.0.2", DateCreate: now, LastSeen: now, Remember: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`DELETE FROM usersessions WHERE uuid=?`, "sessions-test-laptop")
+	err = f.AddUsersSession(userID, &model.UserSession{Uuid: "sessions-test-expired", ExpirySession: now.Add(-time.Hour), Agent: "old agent", IP: "10.0.0.3", DateCreate: now, LastSeen: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.DB.Exec(`DELETE FROM usersessions WHERE uuid=?`, "sessions-test-expired")
+
+	err = f.UpdateSessionLastSeen("sessions-test-phone", now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := f.GetUserSessions(userID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSessions := map[string]*model.UserSession{}
+	var order []string
+	for _, session := range sessions {
+		if session.Uuid == "sessions-test-phone" || session.Uuid == "sessions-test-laptop" || session.Uuid == "sessions-test-expired" {
+			testSessions[session.Uuid] = session
+			order = append(order, session.Uuid)
+		}
+	}
+	if len(order) != 2 || order[0] != "sessions-test-phone" {
+		t.Fatalf("got the sessions %v, want the phone and the laptop, the recently used phone first", order)
+	}
+	phone := testSessions["sessions-test-phone"]
+	if phone.Agent != "phone agent" || phone.IP != "10.0.0.1" || phone.Remember {
+		t.Errorf("got the phone session with the agent '%s', the ip '%s' and remember %t", phone.Agent, phone.IP, phone.Remember)
+	}
+	if !testSessions["sessions-test-laptop"].Remember {
+		t.Errorf("the laptop session is not remembered")
+	}
+
+	user, err := f.GetUserByUUID("sessions-test-phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.SessionLastSeen.Equal(now.Add(time.Minute)) || user.RememberSession {
+		t.Errorf("got the user's session last seen at %s and remember %t, want %s and false", user.SessionLastSeen, user.RememberSession, now.Add(time.Minute))
+	}
+
+	_, err = f.DeleteUserSessionByID(userID+1, phone.ID)
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("deleting a session of another user: got error %v, want %v", err, model.ErrNoRecord)
+	}
+	uuid, err := f.DeleteUserSessionByID(userID, phone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uuid != "sessions-test-phone" {
+		t.Errorf("got the deleted session '%s', want 'sessions-test-phone'", uuid)
+	}
+	_, err = f.GetUserByUUID("sessions-test-phone")
+	if !errors.Is(err, model.ErrNoRecord) {
+		t.Errorf("getting the user of a deleted session: got error %v, want %v", err, model.ErrNoRecord)
+	}
+}
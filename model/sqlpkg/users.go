@@ -8,7 +8,7 @@ import (
 	"forum/model"
 )
 
-const ConstFields = ` u.id, u.name, u.email, u.dateCreate, u.dateBirth, u.gender, u.firstName, u.lastName `
+const ConstFields = ` u.id, u.name, u.email, u.dateCreate, u.dateBirth, u.gender, u.firstName, u.lastName, u.role, u.dateBan IS NOT NULL, u.suspendedUntil, u.mutedUntil, u.verified `
 
 /*
 returns list of all users in DB
@@ -31,7 +31,7 @@ func (f *ForumModel) GetFilteredUsers(userIDs model.IdChecker) ([]*model.User, e
 	var users []*model.User
 	for rows.Next() {
 		user := &model.User{}
-		err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName)
+		err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified)
 		if err != nil {
 			return nil, err
 		}
@@ -55,13 +55,14 @@ if there is no message from a user, shuch users will sort by their names.
 func (f *ForumModel) GetFilteredUsersOrderedByMessagesToGivenUser(userIDs model.IdChecker, forUserID int) ([]*model.User, error) {
 	q := `SELECT u.id, u.name , max(ms.dateCreate) FROM users u
 	LEFT JOIN (SELECT  mb.id as mbID,  mb.userID as UserID FROM chat_members mb
-		WHERE mb.userID!=? AND mb.chatID IN (SELECT chatID FROM chat_members WHERE userID=?)) 
+		WHERE mb.userID!=? AND mb.chatID IN (SELECT chatID FROM chat_members WHERE userID=?)
+		AND mb.chatID IN (SELECT id FROM chats WHERE type=?)) 
 		userMb ON u.id=userMb.UserID 
 	LEFT JOIN chat_messages ms ON userMb.mbID=ms.chat_membersID
 	WHERE u.id!=?
 	GROUP BY u.id  ORDER BY ms.dateCreate desc, lower(name)`
 
-	rows, err := f.DB.Query(q, forUserID, forUserID, forUserID)
+	rows, err := f.DB.Query(q, forUserID, forUserID, model.PRIVATE_CHAT, forUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +101,7 @@ func (f *ForumModel) getUsersByCondition(condition string) ([]*model.User, error
 	var users []*model.User
 	for rows.Next() {
 		user := &model.User{}
-		err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName)
+		err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +126,7 @@ func (f *ForumModel) GetUserByID(id int) (*model.User, error) {
 	var uuidInDB sql.NullString
 	var expirySessionInDB sql.NullTime
 	row := f.DB.QueryRow(q, id)
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Password, &uuidInDB, &expirySessionInDB)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified, &user.Password, &uuidInDB, &expirySessionInDB)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, model.ErrNoRecord
@@ -150,7 +151,7 @@ func (f *ForumModel) GetUserByName(name string) (*model.User, error) {
 	var uuidInDB sql.NullString
 	var expirySessionInDB sql.NullTime
 	row := f.DB.QueryRow(q, name)
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Password, &uuidInDB, &expirySessionInDB)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified, &user.Password, &uuidInDB, &expirySessionInDB)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, model.ErrNoRecord
@@ -177,7 +178,7 @@ func (f *ForumModel) GetUserByEmail(email string) (*model.User, error) {
 	var uuidInDB sql.NullString
 	var expirySessionInDB sql.NullTime
 	row := f.DB.QueryRow(q, email)
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Password, &uuidInDB, &expirySessionInDB)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified, &user.Password, &uuidInDB, &expirySessionInDB)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, model.ErrNoRecord
@@ -195,14 +196,14 @@ func (f *ForumModel) GetUserByEmail(email string) (*model.User, error) {
 returns a user from DB by the email
 */
 func (f *ForumModel) GetUserByUUID(uuid string) (*model.User, error) {
-	q := `SELECT ` + ConstFields + `, s.uuid, s.expirySession 
+	q := `SELECT ` + ConstFields + `, s.uuid, s.expirySession, s.lastSeen, s.remember 
 	FROM  users u INNER JOIN usersessions s ON u.id=s.userID WHERE s.uuid=?`
 
 	user := &model.User{}
 	var uuidInDB sql.NullString
-	var expirySessionInDB sql.NullTime
+	var expirySessionInDB, lastSeenInDB sql.NullTime
 	row := f.DB.QueryRow(q, uuid)
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &uuidInDB, &expirySessionInDB)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.DateCreate, &user.DateBirth, &user.Gender, &user.FirstName, &user.LastName, &user.Role, &user.Banned, &user.SuspendedUntil, &user.MutedUntil, &user.Verified, &uuidInDB, &expirySessionInDB, &lastSeenInDB, &user.RememberSession)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, model.ErrNoRecord
@@ -212,6 +213,7 @@ func (f *ForumModel) GetUserByUUID(uuid string) (*model.User, error) {
 
 	user.Uuid = uuidInDB.String
 	user.ExpirySession = expirySessionInDB.Time
+	user.SessionLastSeen = lastSeenInDB.Time
 
 	return user, nil
 }
@@ -220,8 +222,8 @@ func (f *ForumModel) GetUserByUUID(uuid string) (*model.User, error) {
 inserts the new user into DB. It doesn't do any check of unique data. But if DB have some restricts, it will return an error
 */
 func (f *ForumModel) InsertUser(user *model.User) (int, error) {
-	q := `INSERT INTO users  (name, email, password, dateCreate, dateBirth, gender, firstName, lastName) VALUES (?,?,?,?,?,?,?,?)`
-	res, err := f.DB.Exec(q, user.Name, user.Email, user.Password, user.DateCreate, user.DateBirth, user.Gender, user.FirstName, user.LastName)
+	q := `INSERT INTO users  (name, email, password, dateCreate, dateBirth, gender, firstName, lastName, verified) VALUES (?,?,?,?,?,?,?,?,?)`
+	res, err := f.DB.Exec(q, user.Name, user.Email, user.Password, user.DateCreate, user.DateBirth, user.Gender, user.FirstName, user.LastName, user.Verified)
 	if err != nil {
 		return 0, err
 	}
@@ -234,11 +236,11 @@ func (f *ForumModel) InsertUser(user *model.User) (int, error) {
 }
 
 /*
-adds a session uuid to the user with the given ID
+adds the session to the user with the given ID, the session keeps the user agent and the IP address of the device
 */
-func (f *ForumModel) AddUsersSession(id int, uuid string, expirySession time.Time) error {
-	q := `INSERT INTO usersessions (userID, uuid,expirySession,agent) VALUES (?,?,?,?)`
-	_, err := f.DB.Exec(q, id, uuid, expirySession, "")
+func (f *ForumModel) AddUsersSession(id int, session *model.UserSession) error {
+	q := `INSERT INTO usersessions (userID, uuid, expirySession, agent, ip, dateCreate, lastSeen, remember) VALUES (?,?,?,?,?,?,?,?)`
+	_, err := f.DB.Exec(q, id, session.Uuid, session.ExpirySession, session.Agent, session.IP, session.DateCreate, session.LastSeen, session.Remember)
 	if err != nil {
 		return err
 	}
@@ -259,6 +261,24 @@ func (f *ForumModel) DeleteUsersSession(uuid string) error {
 	return f.checkUnique(res)
 }
 
+/*
+deletes all the sessions of the user, so the user is logged out on all the devices
+*/
+func (f *ForumModel) DeleteUserSessions(userID int) error {
+	q := `DELETE FROM usersessions WHERE userID=?`
+	_, err := f.DB.Exec(q, userID)
+	return err
+}
+
+/*
+deletes all the sessions of the user except the session 'uuid', so the user is logged out on the other devices
+*/
+func (f *ForumModel) DeleteUserSessionsExcept(userID int, uuid string) error {
+	q := `DELETE FROM usersessions WHERE userID=? AND uuid!=?`
+	_, err := f.DB.Exec(q, userID, uuid)
+	return err
+}
+
 /*
 check if a user with the given name exists,  returns nil only if there is exactly one user
 */
@@ -301,10 +321,10 @@ func (f *ForumModel) AddUser(user *model.User) (int, error) {
 }
 
 /*
-changes an email of the user with the given id
+changes an email of the user with the given id, the new email has to be verified again
 */
 func (f *ForumModel) ChangeUsersEmail(id int, email string) error {
-	err := f.changeUsersField(id, "email", email)
+	err := f.changeUsersEmail(id, email)
 	if err != nil {
 		errUnique := f.CheckUserByEmail(email)
 		if errUnique == nil {
@@ -314,6 +334,16 @@ func (f *ForumModel) ChangeUsersEmail(id int, email string) error {
 	return err
 }
 
+func (f *ForumModel) changeUsersEmail(id int, email string) error {
+	q := `UPDATE users SET email=?, verified=FALSE WHERE id=?`
+	res, err := f.DB.Exec(q, email, id)
+	if err != nil {
+		return err
+	}
+
+	return f.checkUnique(res)
+}
+
 /*
 changes a password of the user with the given id
 */
@@ -321,6 +351,19 @@ func (f *ForumModel) ChangeUsersPassword(id int, password string) error {
 	return f.changeUsersField(id, "password", password)
 }
 
+/*
+changes the personal data of the user with the given id
+*/
+func (f *ForumModel) ChangeUsersPersonalData(id int, firstName, lastName, gender string, dateBirth time.Time) error {
+	q := `UPDATE users SET firstName=?, lastName=?, gender=?, dateBirth=? WHERE id=?`
+	res, err := f.DB.Exec(q, firstName, lastName, gender, dateBirth, id)
+	if err != nil {
+		return err
+	}
+
+	return f.checkUnique(res)
+}
+
 /*
 changes a field in the users table for the user with the given id
 */
@@ -20,9 +20,9 @@ func TestAddUserSession(t *testing.T) {
 	f := ForumModel{db}
 
 	fmt.Println("--- add a session to the user 1 ---")
-	fmt.Println(f.AddUsersSession(1, "ses1", time.Now()))
+	fmt.Println(f.AddUsersSession(1, &model.UserSession{Uuid: "ses1", ExpirySession: time.Now()}))
 	fmt.Println("--- add a session to the user 10(not existing) ---")
-	fmt.Println(f.AddUsersSession(10, "ses1", time.Now()))
+	fmt.Println(f.AddUsersSession(10, &model.UserSession{Uuid: "ses1", ExpirySession: time.Now()}))
 }
 
 func TestDeleteUsersSession(t *testing.T) {
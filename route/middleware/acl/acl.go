@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"forum/application"
 	"forum/errorhandle"
 	"forum/logger"
+	"forum/model"
 	"forum/session"
 )
 
@@ -15,6 +17,9 @@ type key string
 
 const SessionKey key = "usersession"
 
+// the prefix of the static files routes, which don't need the user's session
+const STATIC_PREFIX = "/static/"
+
 // DisallowAuth does not allow authenticated users to access the page
 func DisallowAuth(app *application.Application) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
@@ -43,10 +48,43 @@ func DisallowAnon(app *application.Application) func(h http.Handler) http.Handle
 	}
 }
 
-// Add user to the context if any
+// RequireRole allows access only to logged in users with the given role or a higher one.
+// The role is read from DB, so a changed role takes effect right away
+func RequireRole(app *application.Application, role model.Role) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sess := r.Context().Value(SessionKey).(*session.Session)
+			if !sess.IsLoggedin() {
+				app.InfoLog.Printf("Unauthorized access for anonymous user")
+				errorhandle.Forbidden(app, w, r)
+				return
+			}
+
+			userRole, err := app.ForumData.GetUserRole(sess.User.ID)
+			if err != nil {
+				errorhandle.ServerError(app, w, r, fmt.Sprintf("getting the role of the user %d failed", sess.User.ID), err)
+				return
+			}
+			if !userRole.IsAtLeast(role) {
+				app.InfoLog.Printf("Unauthorized access for the user '%s' with the role '%s', the role '%s' is required", sess.User.Name, userRole, role)
+				errorhandle.Forbidden(app, w, r)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Add user to the context if any. The static files are served without reading the session from DB
 func AddUser(app *application.Application) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, STATIC_PREFIX) {
+				ctx := context.WithValue(r.Context(), SessionKey, session.GetNotloggedinSession())
+				h.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			sess, err := session.Get(app, w, r)
 			if err != nil {
 				errorhandle.ServerError(app, w, r, fmt.Sprintf("func %s failed ", logger.GetCurrentFuncName()), err)
@@ -0,0 +1,38 @@
+package limit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"forum/application"
+	"forum/controllers/ratelimit"
+	"forum/errorhandle"
+	"forum/route/middleware/acl"
+	"forum/session"
+)
+
+// Limit allows the requests of a user, or of an IP address for anonymous users, within the budget of the kind.
+// The other requests get the status 429 with the Retry-After header
+func Limit(app *application.Application, kind string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := 0
+			if sess, ok := r.Context().Value(acl.SessionKey).(*session.Session); ok && sess.IsLoggedin() {
+				userID = sess.User.ID
+			}
+			key := ratelimit.Key(userID, session.DeviceOf(app, r).IP)
+
+			result := app.HTTPLimiter.Allow(key, kind, time.Now())
+			if result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			errorhandle.ClientError(app, w, r, http.StatusTooManyRequests, fmt.Sprintf("the request '%s' of '%s' is rejected by the rate limit, retry after %s", kind, key, result.RetryAfter))
+		})
+	}
+}
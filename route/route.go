@@ -6,7 +6,9 @@ import (
 	"forum/application"
 	"forum/controllers"
 	"forum/logger"
+	"forum/model"
 	"forum/route/middleware/acl"
+	"forum/route/middleware/limit"
 	"forum/route/middleware/log"
 	"forum/route/middleware/method"
 )
@@ -56,13 +58,22 @@ func Load(app *application.Application) http.Handler {
 func routes(app *application.Application) *http.ServeMux {
 	var (
 		GET  = method.Method(app, "GET")  // Only allow GET requests
-		//POST = method.Method(app, "POST") // Only allow POST requests
+		POST = method.Method(app, "POST") // Only allow POST requests
 	)
 
 	r := Mux{Mux: http.NewServeMux()}
 
 	r.Handle("/", GET).ThenFunc(controllers.Index(app))
-	r.Handle("/ws").ThenFunc(controllers.IndexWs(app)) // TODO-Solved do we need GET here?-Answer: no, gorilla/websocket checks it in Upgrader
+	r.Handle("/ws", limit.Limit(app, "ws")).ThenFunc(controllers.IndexWs(app)) // TODO-Solved do we need GET here?-Answer: no, gorilla/websocket checks it in Upgrader
+
+	r.Handle("/upload", POST, acl.DisallowAnon(app), limit.Limit(app, "upload")).ThenFunc(controllers.UploadImages(app))
+	r.Handle(controllers.IMAGES_URL_PREFIX, GET, acl.DisallowAnon(app)).Then(controllers.ServeImages(app))
+
+	r.Handle("/password/reset", POST, limit.Limit(app, "passwordReset")).ThenFunc(controllers.RequestPasswordReset(app))
+	r.Handle("/password/reset/confirm", POST, limit.Limit(app, "passwordReset")).ThenFunc(controllers.ConfirmPasswordReset(app))
+	r.Handle("/verify", GET, limit.Limit(app, "verify")).ThenFunc(controllers.VerifyEmail(app))
+
+	r.Handle("/moderation/log", GET, acl.RequireRole(app, model.ROLE_MODERATOR)).ThenFunc(controllers.ModerationLog(app))
 
 	// r.Handle("/chat/", GET, acl.DisallowAnon(app)).ThenFunc(controllers.OpenChat(app))
 	// r.Handle("/chatws/", acl.DisallowAnon(app)).ThenFunc(controllers.HandleChatWs(app))
@@ -73,16 +84,16 @@ func routes(app *application.Application) *http.ServeMux {
 	// r.Handle("/reactions", POST).ThenFunc(controllers.ReactionsPOST(app)) // no alc, contoller handles it because need unifed way to report errors (session expired)
 
 	// GitHub
-	// r.Handle("/login/github").ThenFunc(controllers.OAuthGitHub(app))
-	// r.Handle("/login/github/callback").ThenFunc(controllers.OAuthGitHubCallback(app))
+	r.Handle("/login/github", GET, limit.Limit(app, "oauth")).ThenFunc(controllers.OAuthGitHub(app))
+	r.Handle("/login/github/callback", GET, limit.Limit(app, "oauth")).ThenFunc(controllers.OAuthGitHubCallback(app))
 
-	// // Google
-	// r.Handle("/login/google").ThenFunc(controllers.OAuthGoogle(app))
-	// r.Handle("/login/google/callback").ThenFunc(controllers.OAuthGoogleCallback(app))
+	// Google
+	r.Handle("/login/google", GET, limit.Limit(app, "oauth")).ThenFunc(controllers.OAuthGoogle(app))
+	r.Handle("/login/google/callback", GET, limit.Limit(app, "oauth")).ThenFunc(controllers.OAuthGoogleCallback(app))
 
 	staticDirectory := http.Dir("webui/static")
 	staticServer := http.FileServer(staticDirectory)
-	r.Handle("/static/").Then(http.StripPrefix("/static/", staticServer))
+	r.Handle(acl.STATIC_PREFIX).Then(http.StripPrefix(acl.STATIC_PREFIX, staticServer))
 
 	return r.Mux
 }
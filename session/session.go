@@ -3,7 +3,9 @@ package session
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"forum/application"
@@ -20,26 +22,145 @@ const (
 	Notloggedin
 )
 
-const (
-	EXP_SESSION               = 24 * time.Hour
-	TIME_BEFORE_AFTER_REFRESH = 30 * time.Second
-)
-
 const SESSION_TOKEN = "forum_session_id"
 
+const maxAgentLength = 512
+
+// the last seen time of a session is updated in DB not more often than once in the period
+const LAST_SEEN_PERIOD = time.Minute
+
+// Device is the browser, which the user logs in from
+type Device struct {
+	Agent string
+	IP    string
+}
+
+/*
+DeviceOf returns the device, which sent the request. The IP address is the address of the direct peer,
+unless the peer is one of the app's trusted proxies
+*/
+func DeviceOf(app *application.Application, r *http.Request) Device {
+	ip := clientIP(r, app.TrustedProxies)
+	agent := r.UserAgent()
+	if len(agent) > maxAgentLength {
+		agent = agent[:maxAgentLength]
+	}
+	return Device{Agent: agent, IP: ip}
+}
+
+/*
+returns the IP address of the client. If the request comes from a trusted proxy, the addresses in X-Forwarded-For
+are checked from the right, and the first address, which is not a trusted proxy, is the client's one.
+The addresses on the left of it can be set by the client, so they are not used
+*/
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		forwardedIP := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(forwardedIP) == nil {
+			break
+		}
+		ip = forwardedIP
+		if !isTrustedProxy(ip, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
 type Session struct {
-	loginStatus LoginStatus 
+	loginStatus LoginStatus
 	User        *model.User `json:"user"`
+	// the session expires at the absolute expiry time of the user's session or after the idle timeout since the last use
+	expiry time.Time
 }
 
 func (s *Session) isExpired() bool {
-	exp := s.User.ExpirySession
-	return exp.Before(time.Now())
+	return s.expiry.Before(time.Now())
+}
+
+/*
+sets the expiry time of the session by the absolute expiry time and the last use of the session.
+The sessions with "remember me" and the sessions without the last use time have no idle timeout
+*/
+func (s *Session) setExpiry(app *application.Application) {
+	s.expiry = s.User.ExpirySession
+	if s.User.RememberSession || s.User.SessionLastSeen.IsZero() {
+		return
+	}
+	idleExpiry := s.User.SessionLastSeen.Add(app.Session.IdleTimeout)
+	if idleExpiry.Before(s.expiry) {
+		s.expiry = idleExpiry
+	}
+}
+
+/*
+marks the session as used at the time 'now', so the idle timeout of the session starts again
+*/
+func (s *Session) Touch(app *application.Application, now time.Time) error {
+	err := app.ForumData.UpdateSessionLastSeen(s.User.Uuid, now)
+	if err != nil {
+		return fmt.Errorf("updating the last seen time of the session failed: %w", err)
+	}
+	s.User.SessionLastSeen = now
+	s.setExpiry(app)
+	return nil
 }
 
-func (s *Session) timeToExpired() time.Duration {
-	exp := s.User.ExpirySession
-	return time.Until(exp)
+/*
+reads the session of a logged in user from DB again, so the use of the session by the other connections is taken into account.
+Returns false if the session is expired or deleted, the expired session is deleted from DB
+*/
+func (s *Session) Check(app *application.Application) (bool, error) {
+	if s == nil || s.loginStatus != Loggedin || s.User == nil {
+		return false, nil
+	}
+	user, err := app.ForumData.GetUserByUUID(s.User.Uuid)
+	if errors.Is(err, model.ErrNoRecord) {
+		s.loginStatus = Experied
+		return false, nil
+	}
+	if err != nil {
+		return true, fmt.Errorf("getting a user by uuid failed: %w", err)
+	}
+
+	s.User.ExpirySession = user.ExpirySession
+	s.User.SessionLastSeen = user.SessionLastSeen
+	s.setExpiry(app)
+	if !s.isExpired() {
+		return true, nil
+	}
+
+	s.loginStatus = Experied
+	err = app.ForumData.DeleteUsersSession(s.User.Uuid)
+	if err != nil && !errors.Is(err, model.ErrNoRecord) {
+		return false, fmt.Errorf("deleting the expired session failed: %w", err)
+	}
+	return false, nil
 }
 
 func (s *Session) IsLoggedin() bool {
@@ -78,7 +199,7 @@ func (s *Session) Tidy(app *application.Application) (LoginStatus, error) {
 		s.User = nil
 		return Notloggedin, nil
 	}
-	return  s.loginStatus , nil
+	return s.loginStatus, nil
 }
 
 func (s *Session) GetStatus() string {
@@ -95,16 +216,16 @@ func (s *Session) GetStatus() string {
 }
 
 func GetNotloggedinSession() *Session {
-	return &Session{Notloggedin, nil}
+	return &Session{loginStatus: Notloggedin}
 }
 
 /*
 returns session which contains status of login and uses's data if it's logged in.
-If it is left lrss than 30 sec to expiried time, it will refresh the session
+The session is marked as used, so its idle timeout starts again, if it was marked more than LAST_SEEN_PERIOD ago.
 If an error occurs it will response to the client with error status and return the error
 */
 func Get(app *application.Application, w http.ResponseWriter, r *http.Request) (*Session, error) {
-	session := &Session{Notloggedin, nil}
+	session := &Session{loginStatus: Notloggedin}
 	cook, err := r.Cookie(SESSION_TOKEN)
 	if err != nil && err != http.ErrNoCookie {
 		return nil, fmt.Errorf("getting cookie failed: '%s', url: '%s'", err, r.URL)
@@ -123,7 +244,17 @@ func Get(app *application.Application, w http.ResponseWriter, r *http.Request) (
 		}
 		return nil, fmt.Errorf("getting a user by uuid failed: %w", err)
 	}
+	if user.IsSuspended(time.Now()) {
+		// a suspended user cannot use the sessions, which were not deleted yet
+		err := app.ForumData.DeleteUsersSession(user.Uuid)
+		if err != nil {
+			return nil, fmt.Errorf("deleting the session of the suspended user failed: %w", err)
+		}
+		clearCookie(w)
+		return session, nil // session status = notloggedin
+	}
 	session.User = user
+	session.setExpiry(app)
 
 	if session.isExpired() {
 		// delete the session & return expiried status
@@ -133,52 +264,79 @@ func Get(app *application.Application, w http.ResponseWriter, r *http.Request) (
 			return nil, fmt.Errorf("deleting the expired session failed: %w", err)
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:    SESSION_TOKEN,
-			Value:   "",
-			Expires: time.Now(),
-		})
+		clearCookie(w)
 		session.loginStatus = Experied
 		return session, nil
 	}
 
-	if session.timeToExpired() < TIME_BEFORE_AFTER_REFRESH {
-		// refresh the session
-		session, err = New(app, w, user)
+	// user was found and their time was not expired, the idle timeout slides:
+	now := time.Now()
+	if now.Sub(user.SessionLastSeen) >= LAST_SEEN_PERIOD {
+		err = session.Touch(app, now)
 		if err != nil {
-			return nil, fmt.Errorf("session creating failed: %w", err)
+			return nil, err
 		}
-
-		return session, nil
 	}
-
-	// user was found and their time was not expired or was renewed:
 	session.loginStatus = Loggedin
 	return session, nil
 }
 
-func New(app *application.Application, w http.ResponseWriter, user *model.User) (*Session, error) {
-	expiresAt := time.Now().Add(EXP_SESSION)
+/*
+creates a new session of the user on the device and sets the session cookie.
+A remembered session lives longer, has no idle timeout and its cookie is kept after the browser is closed
+*/
+func New(app *application.Application, w http.ResponseWriter, user *model.User, device Device, remember bool) (*Session, error) {
+	now := time.Now()
+	lifetime := app.Session.AbsoluteTimeout
+	if remember {
+		lifetime = app.Session.RememberTimeout
+	}
+	expiresAt := now.Add(lifetime)
 	newSessionToken, err := uuid.NewV4()
 	if err != nil {
 		return nil, fmt.Errorf("UUID creating failed: %w", err)
 	}
 
-	err = app.ForumData.AddUsersSession(user.ID, newSessionToken.String(), expiresAt)
+	err = app.ForumData.AddUsersSession(user.ID, &model.UserSession{
+		Uuid:          newSessionToken.String(),
+		Agent:         device.Agent,
+		IP:            device.IP,
+		DateCreate:    now,
+		LastSeen:      now,
+		ExpirySession: expiresAt,
+		Remember:      remember,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("adding session failed: %w", err)
 	}
 
 	app.InfoLog.Printf("session tocken '%s' is added for the user ID '%d'", newSessionToken.String(), user.ID)
 
-	http.SetCookie(w, &http.Cookie{
-		Name:    SESSION_TOKEN,
-		Value:   newSessionToken.String(),
-		Expires: expiresAt,
-	})
+	cookie := &http.Cookie{
+		Name:  SESSION_TOKEN,
+		Value: newSessionToken.String(),
+		Path:  "/", // the session is created also on the OAuth callback paths
+	}
+	if remember {
+		cookie.Expires = expiresAt
+	}
+	http.SetCookie(w, cookie)
 
 	user.Uuid = newSessionToken.String()
 	user.ExpirySession = expiresAt
+	user.SessionLastSeen = now
+	user.RememberSession = remember
 
-	return &Session{loginStatus: Loggedin, User: user}, nil
+	session := &Session{loginStatus: Loggedin, User: user}
+	session.setExpiry(app)
+	return session, nil
+}
+
+func clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:    SESSION_TOKEN,
+		Value:   "",
+		Path:    "/",
+		Expires: time.Now(),
+	})
 }
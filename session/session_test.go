@@ -0,0 +1,71 @@
+package session
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"forum/application"
+	"forum/model"
+)
+
+func TestSetExpiry(t *testing.T) {
+	app := &application.Application{Session: application.SessionConfig{IdleTimeout: time.Hour}}
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		user        model.User
+		wantExpired bool
+	}{
+		{"active", model.User{ExpirySession: now.Add(time.Hour), SessionLastSeen: now.Add(-time.Minute)}, false},
+		{"idle", model.User{ExpirySession: now.Add(time.Hour), SessionLastSeen: now.Add(-2 * time.Hour)}, true},
+		{"absolute timeout", model.User{ExpirySession: now.Add(-time.Minute), SessionLastSeen: now}, true},
+		{"remembered idle", model.User{ExpirySession: now.Add(time.Hour), SessionLastSeen: now.Add(-2 * time.Hour), RememberSession: true}, false},
+		{"remembered absolute timeout", model.User{ExpirySession: now.Add(-time.Minute), SessionLastSeen: now, RememberSession: true}, true},
+	}
+	for _, test := range tests {
+		session := &Session{loginStatus: Loggedin, User: &test.user}
+		session.setExpiry(app)
+		if session.isExpired() != test.wantExpired {
+			t.Errorf("%s: got expired %t, want %t", test.name, session.isExpired(), test.wantExpired)
+		}
+		if session.IsLoggedin() == test.wantExpired {
+			t.Errorf("%s: got logged in %t, want %t", test.name, session.IsLoggedin(), !test.wantExpired)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct client", "203.0.113.5:4000", "", "203.0.113.5"},
+		{"header of an untrusted peer", "203.0.113.5:4000", "198.51.100.7", "203.0.113.5"},
+		{"client behind the proxy", "10.0.0.1:4000", "198.51.100.7", "198.51.100.7"},
+		{"spoofed address on the left", "10.0.0.1:4000", "1.2.3.4, 198.51.100.7", "198.51.100.7"},
+		{"chain of the trusted proxies", "10.0.0.1:4000", "198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"proxy without the header", "10.0.0.1:4000", "", "10.0.0.1"},
+		{"invalid header", "10.0.0.1:4000", "unknown", "10.0.0.1"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		if ip := clientIP(r, trusted); ip != test.want {
+			t.Errorf("%s: got the IP '%s', want '%s'", test.name, ip, test.want)
+		}
+	}
+}
@@ -9,11 +9,21 @@ export default class ChatView {
         this.throttleAndDebouncedHandleScroll = throttleAndDebounce(this.handleScroll.bind(this), 300);
         
         this.webSocketManager.on("chatPortionReply", this.handleChatPortionReply)
-
+        this.webSocketManager.on("typingStarted", this.handleTypingStarted)
+        this.webSocketManager.on("typingStopped", this.handleTypingStopped)
+        this.webSocketManager.on("chatMessageEdited", this.handleMessageEdited)
+        this.webSocketManager.on("chatMessageDeleted", this.handleMessageDeleted)
+        this.webSocketManager.on("editChatMessageReply", this.handleMessageEdited)
+        this.webSocketManager.on("deleteChatMessageReply", this.handleMessageDeleted)
+        this.webSocketManager.on("messageDelivered", this.handleMessageDelivered)
+        this.webSocketManager.on("messageRead", this.handleMessageRead)
+
+        this.chatID = 0;
         this.topMessageID = 0;
         this.chatRecipientOnline = true;
         this.gettingMoreMessages = false;
         this.pendingSentMessageData = {};
+        this.messageReceipts = {};
         this.currentSessionUserData = {};
         this.currentChatRecipientData = {};
     }
@@ -50,7 +60,10 @@ export default class ChatView {
     // --------------------------- INITALIZING CHAT ---------------------------
 
     //Runs when opening chat
-    initializeChat = (recipientUserID, recipientUsername, messages) => {
+    initializeChat = (chatID, recipientUserID, recipientUsername, messages) => {
+
+        //Store chat ID to address messages and requests to this chat
+        this.chatID = chatID;
 
         //Store recipient data
         this.setChatUsername(recipientUsername);
@@ -70,6 +83,12 @@ export default class ChatView {
 
         this.parsePreviousMessagesData(messages);
 
+        //The newest message of the recipient is shown now, so the chat is read up to it
+        const lastReceivedMessage = messages.find((message) => String(message.author.id) !== this.currentSessionUserData.userID);
+        if (lastReceivedMessage) {
+            this.webSocketManager.sendMessageRead(lastReceivedMessage.id);
+        }
+
         this.scrollToChatBottom();
 
         //If there may be more old message, listen for scroll
@@ -104,9 +123,14 @@ export default class ChatView {
             }
 
             const messageDate = this.formatDateToLocalString(new Date(message.dateCreate));
-            const messageContent = message.content;
+            let messageContent = message.content;
+            if (message.dateDelete) {
+                messageContent = STRINGS.DELETED_CHAT_MESSAGE;
+            } else if (message.dateEdit) {
+                messageContent += STRINGS.EDITED_CHAT_MESSAGE_MARK;
+            }
 
-            this.addMessageToDOM(userClass, username, messageDate, messageContent);
+            this.addMessageToDOM(userClass, username, messageDate, messageContent, false, message.id);
 
             //Store the top message ID for requesting more previous ones from server
             if (i === messages.length - 1) {
@@ -115,9 +139,9 @@ export default class ChatView {
         }
     }
 
-    generateMessageHTML = (userClass, username, messageDate, messageContent) => {
-        return `<div class="message ${userClass}">
-                    <div class="message-info">${username} - ${messageDate}</div>
+    generateMessageHTML = (userClass, username, messageDate, messageContent, messageID) => {
+        return `<div class="message ${userClass}" data-message-id="${messageID}">
+                    <div class="message-info">${username} - ${messageDate} <span class="message-status"></span></div>
                     <div class="message-bubble">
                         <span class="message-content">${messageContent}</span>
                     </div>
@@ -125,8 +149,8 @@ export default class ChatView {
     }
 
     //For adding message to DOM. Possibility to choose if to add to the top or bottom of message list
-    addMessageToDOM = (userClass, username, messageDate, messageContent, addToEnd = false) => {
-        const messageHTML = this.generateMessageHTML(userClass, username, messageDate, messageContent);
+    addMessageToDOM = (userClass, username, messageDate, messageContent, addToEnd = false, messageID = 0) => {
+        const messageHTML = this.generateMessageHTML(userClass, username, messageDate, messageContent, messageID);
         let direction;
         if (!addToEnd) {
             direction = "afterbegin";
@@ -134,6 +158,7 @@ export default class ChatView {
             direction = "beforeend";
         };
         this.DOMElements.chatMessages.insertAdjacentHTML(direction, messageHTML);
+        this.showMessageStatus(messageID);
     }
 
     // --------------------------- HANDLE SCROLLING ---------------------------
@@ -143,7 +168,7 @@ export default class ChatView {
         const offset = this.DOMElements.chatMessages.clientHeight / 2;
         if (!this.gettingMoreMessages && this.DOMElements.chatMessages.scrollTop <= offset) {
             this.gettingMoreMessages = true;
-            this.webSocketManager.sendChatPortionRequest(this.topMessageID);
+            this.webSocketManager.sendChatPortionRequest(this.chatID, this.topMessageID);
         }
     }
 
@@ -173,6 +198,11 @@ export default class ChatView {
 
     //When receiving more old messages from server
     handleChatPortionReply = (payload) => {
+        //Portion may belong to a chat which is not shown anymore
+        if (payload.result !== STRINGS.SUCCESS || payload.data.id !== this.chatID) {
+            return
+        }
+
         setTimeout(() => {
             const currentScrollTopFromBottom = this.DOMElements.chatMessages.scrollHeight - this.DOMElements.chatMessages.scrollTop;
 
@@ -279,7 +309,7 @@ export default class ChatView {
             const isoDate = dateObj.toISOString();
             const message = this.DOMElements.chatInput.value;
             this.pendingSentMessageData = { date: dateObj, messageContent: message };
-            this.webSocketManager.sendChatMessage(isoDate, message);
+            this.webSocketManager.sendChatMessage(this.chatID, isoDate, message);
         }
     }
 
@@ -293,6 +323,13 @@ export default class ChatView {
 
     //Enable and disable send button according to if user has written something to message input
     activateSendBtnIfValidMsg = (event) => {
+        //Server throttles typing events and stops typing itself if no new events come
+        if (this.DOMElements.chatInput.value === "") {
+            this.webSocketManager.sendTypingStopped(this.chatID);
+        } else {
+            this.webSocketManager.sendTypingStarted(this.chatID);
+        }
+
         if (this.validChatMessageWritten() && this.chatRecipientOnline) {
             this.DOMElements.sendMessageButton.classList.add("sendMessageBtnActive");
         } else {
@@ -301,7 +338,7 @@ export default class ChatView {
     }
 
     //When new message has been sent succesfuly, add it to messages list and clear input
-    handleMessageSent = () => {
+    handleMessageSent = (messageID) => {
         this.DOMElements.chatInput.value = "";
         this.DOMElements.chatInput.placeholder = "";
         this.DOMElements.sendMessageButton.classList.remove("sendMessageBtnActive");
@@ -311,7 +348,7 @@ export default class ChatView {
         const username = "You";
         const messageDate = this.formatDateToLocalString(this.pendingSentMessageData.date);
         const messageContent = this.pendingSentMessageData.messageContent;
-        this.addMessageToDOM(userClass, username, messageDate, messageContent, true);
+        this.addMessageToDOM(userClass, username, messageDate, messageContent, true, messageID);
         this.scrollToChatBottom();
     }
 
@@ -327,12 +364,87 @@ export default class ChatView {
     //When reciving new message to opened chat, show it in messages list
     handleReceivingNewMessage = (payload) => {
         this.DOMElements.zeroMessagesText.style.display = "none";
-        const userClass = "otherUser";
-        const username = payload.data.author.name;
+        const ownMessage = String(payload.data.author.id) === this.currentSessionUserData.userID;
+        const userClass = ownMessage ? "currentUser" : "otherUser";
+        const username = ownMessage ? "You" : payload.data.author.name;
         const messageDate = this.formatDateToLocalString(new Date(payload.data.date));
         const messageContent = payload.data.messageContent;
-        this.addMessageToDOM(userClass, username, messageDate, messageContent, true);
+        this.addMessageToDOM(userClass, username, messageDate, messageContent, true, payload.data.id);
         this.scrollToChatBottom();
+
+        //The message is shown in the opened chat, so it is read
+        if (!ownMessage) {
+            this.webSocketManager.sendMessageRead(payload.data.id);
+        }
+    }
+
+    // ------------------------------- RECEIPTS -------------------------------
+
+    //Receipts can come before the reply with the message's ID, so they are kept until the message is shown
+    handleMessageDelivered = (payload) => {
+        if (this.messageReceipts[payload.data.messageID] !== STRINGS.READ_CHAT_MESSAGE_MARK) {
+            this.messageReceipts[payload.data.messageID] = STRINGS.DELIVERED_CHAT_MESSAGE_MARK;
+        }
+        this.showMessageStatus(payload.data.messageID);
+    }
+
+    handleMessageRead = (payload) => {
+        this.messageReceipts[payload.data.messageID] = STRINGS.READ_CHAT_MESSAGE_MARK;
+        this.showMessageStatus(payload.data.messageID);
+    }
+
+    showMessageStatus = (messageID) => {
+        const status = this.messageReceipts[messageID];
+        if (!status) {
+            return
+        }
+        const statusEl = this.DOMElements.chatMessages.querySelector(`.message.currentUser[data-message-id="${messageID}"] .message-status`);
+        if (statusEl) {
+            statusEl.textContent = status;
+        }
+    }
+
+    // ----------------------- EDITED AND DELETED MESSAGES --------------------
+
+    //Update the content of a shown message in place
+    setMessageContent = (chatID, messageID, messageContent) => {
+        if (chatID !== this.chatID) {
+            return
+        }
+        const messageEl = this.DOMElements.chatMessages.querySelector(`.message[data-message-id="${messageID}"] .message-content`);
+        if (messageEl) {
+            messageEl.textContent = messageContent;
+        }
+    }
+
+    handleMessageEdited = (payload) => {
+        if (payload.result !== STRINGS.SUCCESS) {
+            return
+        }
+        this.setMessageContent(payload.data.chatID, payload.data.id, payload.data.messageContent + STRINGS.EDITED_CHAT_MESSAGE_MARK);
+    }
+
+    handleMessageDeleted = (payload) => {
+        if (payload.result !== STRINGS.SUCCESS) {
+            return
+        }
+        this.setMessageContent(payload.data.chatID, payload.data.id, STRINGS.DELETED_CHAT_MESSAGE);
+    }
+
+    // --------------------------- TYPING INDICATOR ---------------------------
+
+    handleTypingStarted = (payload) => {
+        if (payload.data.chatID !== this.chatID) {
+            return
+        }
+        this.DOMElements.chatTitle.textContent = `Chat with ${this.currentChatRecipientData.username} (${payload.data.user.name} is typing...)`;
+    }
+
+    handleTypingStopped = (payload) => {
+        if (payload.data.chatID !== this.chatID) {
+            return
+        }
+        this.setChatUsername(this.currentChatRecipientData.username);
     }
 
     // -------------------------------- HELPERS -------------------------------
@@ -1,8 +1,13 @@
 export const STRINGS = {
     SUCCESS: "success",
+    RESET_TOKEN_PARAM: "resetToken",
     LOGIN: "login",
     DASHBOARD: "dashboard",
     POSTS_LIST: "postsList",
     FULL_POST: "fullPost",
     CHAT: "chat",
+    DELETED_CHAT_MESSAGE: "(message deleted)",
+    EDITED_CHAT_MESSAGE_MARK: " (edited)",
+    DELIVERED_CHAT_MESSAGE_MARK: "✓",
+    READ_CHAT_MESSAGE_MARK: "✓✓",
 }
\ No newline at end of file
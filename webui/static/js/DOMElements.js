@@ -11,6 +11,9 @@ export const DOMElements = {
     loginForm: document.getElementById("loginForm"),
     loginUsernameInput: document.getElementById("loginUsername"),
     goToRegisterButton: document.getElementById("goToRegisterButton"),
+    forgotPasswordButton: document.getElementById("forgotPasswordButton"),
+    passwordResetForm: document.getElementById("passwordResetForm"),
+    passwordResetMessage: document.getElementById("passwordResetMessage"),
 
     // REGISTER VIEW ----------------------------------------------------------
     registerContainer: document.getElementById("registerContainer"),
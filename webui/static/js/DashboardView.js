@@ -144,7 +144,8 @@ export default class DashboardView {
 
     //When navigating away from chat send close chat message to server and reset chat related elements and variables
     handleClosingChat = () => {
-        this.webSocketManager.sendCloseChatRequest();
+        this.webSocketManager.sendCloseChatRequest(this.childViews.chat.chatID);
+        this.childViews.chat.chatID = 0;
         this.childViews.onlineUsers.makePreviousChatUsernameClickable();
         this.currentChatRecipientData = {};
         this.childViews.onlineUsers.removeCurrentChatRecipientData();
@@ -234,7 +235,7 @@ export default class DashboardView {
         this.storeChatRecipientUserData(recipientUserID, recipientUsername);
 
         //Parse previous 10 messages and store new hat recipient ID and username in chat view
-        this.childViews.chat.initializeChat(recipientUserID, recipientUsername, payload.data.message);
+        this.childViews.chat.initializeChat(payload.data.id, recipientUserID, recipientUsername, payload.data.message);
 
         this.switchChildView(STRINGS.CHAT);
     }
@@ -254,11 +255,19 @@ export default class DashboardView {
     handleIncomingChatMessage = (payload) => {
         const senderUsername = payload.data.author.name;
 
+        //Message sent by the current user from another device
+        if (String(payload.data.author.id) === this.currentSessionUserData.userID) {
+            if (this.childViews.chat.chatID === payload.data.chatID) {
+                this.childViews.chat.handleReceivingNewMessage(payload);
+            }
+            return
+        }
+
         //Inform Online Users Sidebar to reorder the users
         this.childViews.onlineUsers.handleListAndArrayOnNewMessage(payload.data.author.id);
     
-        //If chat with the user who sent message is not yet opened, show notification bubble in online users list
-        if (this.currentChatRecipientData.username !== senderUsername) {
+        //If the chat the message belongs to is not shown, show notification bubble in online users list
+        if (this.childViews.chat.chatID !== payload.data.chatID) {
             const senderUsername = payload.data.author.name;
             this.childViews.onlineUsers.showAndIncreaseNotificationsBubble(senderUsername);
         } else { //If chat is already opened, show new message in the chat view
@@ -273,7 +282,7 @@ export default class DashboardView {
         }
 
         this.childViews.onlineUsers.handleListAndArrayOnNewMessage(this.currentChatRecipientData.userID);
-        this.childViews.chat.handleMessageSent();
+        this.childViews.chat.handleMessageSent(payload.data.id);
     }
 
     // ------------------- HANDLING POST RELATED REPLIES ----------------------
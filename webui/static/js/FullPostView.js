@@ -7,6 +7,11 @@ export default class FullPostView {
         this.switchChildView = switchChildView;
         this.webSocketManager = webSocketManager;
         this.webSocketManager.on("newCommentReply", this.handleNewCommentReply);
+        this.webSocketManager.on("commentCreated", this.handleCommentCreated);
+        this.webSocketManager.on("commentEdited", this.handleCommentEdited);
+        this.webSocketManager.on("commentDeleted", this.handleCommentDeleted);
+        this.webSocketManager.on("postEdited", this.handlePostEdited);
+        this.webSocketManager.on("postLocked", this.handlePostLocked);
     }
 
     show() {
@@ -16,6 +21,10 @@ export default class FullPostView {
 
     hide() {
         this.DOMElements.fullPostContainer.style.display = 'none';
+        const postID = Number(this.DOMElements.fullPostIdForComment.value);
+        if (postID) {
+            this.webSocketManager.sendClosePost(postID);
+        }
         this.unbindEventListeners();
         this.resetCommentForm();
     }
@@ -41,9 +50,10 @@ export default class FullPostView {
         if (payload.result !== STRINGS.SUCCESS) {
             return
         }
-        const { id, theme, message: { author: { name: username }, content: content }, commentsQuantity, categories, comments } = payload.data;
+        const { id, theme, message: { author: { name: username }, content: content }, commentsQuantity, categories, comments, locked } = payload.data;
 
         this.updatePostMainData(id, theme, username, commentsQuantity, content);
+        this.setCommentFormLocked(Boolean(locked));
         this.updatePostCategories(categories);
         this.updatePostComments(comments);
     }
@@ -73,20 +83,20 @@ export default class FullPostView {
     updatePostComments = (comments) => {
         this.DOMElements.fullPostCommentsContainer.innerHTML = "";
         if (comments) {
-            comments.reverse().forEach(({ message: { author: { name: commentAuthor }, content: commentText } }) => {
-                this.fullPostAddCommentToDOM(commentAuthor, commentText);
+            comments.reverse().forEach(({ id: commentID, message: { author: { name: commentAuthor }, content: commentText } }) => {
+                this.fullPostAddCommentToDOM(commentID, commentAuthor, commentText);
             })
         }
     }
 
     //Creates DOM elements with author and text for a comment
-    fullPostAddCommentToDOM = (commentAuthor, commentText) => {
-        const commentHTML = this.generateCommentHTML(commentAuthor, commentText)
+    fullPostAddCommentToDOM = (commentID, commentAuthor, commentText) => {
+        const commentHTML = this.generateCommentHTML(commentID, commentAuthor, commentText)
         this.DOMElements.fullPostCommentsContainer.insertAdjacentHTML("beforeend", commentHTML);
     }
 
-    generateCommentHTML = (commentAuthor, commentText) => {
-        return `<div class="comment-box card mb-3 p-3 pt-1 border-0">
+    generateCommentHTML = (commentID, commentAuthor, commentText) => {
+        return `<div class="comment-box card mb-3 p-3 pt-1 border-0" data-comment-id="${commentID}">
                     <div class="card-header d-flex bg-transparent border-0 ps-0 pb-1 pe-0 justify-content-between">
                         <div class="left-section d-flex align-items-center">
                             <span class="fw-semibold fs-7">${commentAuthor}:</span>
@@ -147,6 +157,58 @@ export default class FullPostView {
         this.scrollElementIntoViewIfNeeded(newCommentEl);
     }
 
+    //Comments of other users to the shown post are pushed by server
+    handleCommentCreated = (payload) => {
+        const { id: commentID, postID, message: { author: { name: commentAuthor }, content: commentText } } = payload.data;
+        if (postID !== Number(this.DOMElements.fullPostIdForComment.value)) {
+            return
+        }
+        const commentHTML = this.generateCommentHTML(commentID, commentAuthor, commentText);
+        this.DOMElements.fullPostCommentsContainer.insertAdjacentHTML("afterbegin", commentHTML);
+        const commentAmount = this.DOMElements.fullPostCommentAmount;
+        commentAmount.textContent = Number(commentAmount.textContent) + 1;
+    }
+
+    //Changes of the shown post and its comments are pushed by server
+    handlePostEdited = (payload) => {
+        const { id, theme, content } = payload.data;
+        if (id !== Number(this.DOMElements.fullPostIdForComment.value)) {
+            return
+        }
+        this.DOMElements.fullPostTitle.textContent = theme;
+        this.DOMElements.fullPostContent.textContent = content;
+    }
+
+    handlePostLocked = (payload) => {
+        const { postID, locked } = payload.data;
+        if (postID !== Number(this.DOMElements.fullPostIdForComment.value)) {
+            return
+        }
+        this.setCommentFormLocked(locked);
+    }
+
+    //New comments cannot be added to a locked post
+    setCommentFormLocked = (locked) => {
+        this.DOMElements.newCommentForm.style.display = locked ? 'none' : '';
+    }
+
+    handleCommentEdited = (payload) => {
+        const { id, content } = payload.data;
+        const commentEl = this.DOMElements.fullPostCommentsContainer.querySelector(`[data-comment-id="${id}"] p`);
+        if (commentEl) {
+            commentEl.textContent = content;
+        }
+    }
+
+    handleCommentDeleted = (payload) => {
+        const commentEl = this.DOMElements.fullPostCommentsContainer.querySelector(`[data-comment-id="${payload.data.id}"]`);
+        if (commentEl) {
+            commentEl.remove();
+            const commentAmount = this.DOMElements.fullPostCommentAmount;
+            commentAmount.textContent = Number(commentAmount.textContent) - 1;
+        }
+    }
+
     resetCommentForm = () => {
         this.DOMElements.newCommentInput.value = "";
         this.collapseCommentForm();
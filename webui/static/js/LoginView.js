@@ -1,8 +1,12 @@
+import { STRINGS } from "./ConstantStrings.js";
+
 export default class LoginView {
   constructor(DOMElements, switchView, webSocketManager) {
     this.DOMElements = DOMElements;
     this.switchView = switchView;
     this.webSocketManager = webSocketManager;
+    //The link from the password reset email opens the page with the reset token
+    this.resetToken = new URLSearchParams(window.location.search).get(STRINGS.RESET_TOKEN_PARAM);
   }
 
   show() {
@@ -16,6 +20,7 @@ export default class LoginView {
     this.DOMElements.loginContainer.classList.remove("d-flex");
     this.DOMElements.loginContainer.style.display = "none";
     document.getElementById("logoutSuccessMessage").setAttribute('hidden', '');
+    this.DOMElements.passwordResetMessage.setAttribute('hidden', '');
     this.unbindEventListeners();
     this.unfocusLoginFields();
     this.clearLoginForm();
@@ -26,11 +31,15 @@ export default class LoginView {
   bindEventListeners = () => {
     this.DOMElements.loginForm.addEventListener("submit", this.handleLoginFormSubmit);
     this.DOMElements.goToRegisterButton.addEventListener("click", this.handleGoToRegisterClick);
+    this.DOMElements.forgotPasswordButton.addEventListener("click", this.handleForgotPasswordClick);
+    this.DOMElements.passwordResetForm.addEventListener("submit", this.handlePasswordResetFormSubmit);
   }
 
   unbindEventListeners = () => {
     this.DOMElements.loginForm.removeEventListener("submit", this.handleLoginFormSubmit);
     this.DOMElements.goToRegisterButton.removeEventListener("click", this.handleGoToRegisterClick);
+    this.DOMElements.forgotPasswordButton.removeEventListener("click", this.handleForgotPasswordClick);
+    this.DOMElements.passwordResetForm.removeEventListener("submit", this.handlePasswordResetFormSubmit);
   }
 
   // ----------------------------- LOADING SCREEN -----------------------------
@@ -60,6 +69,11 @@ export default class LoginView {
       this.DOMElements.loginForm.style.opacity = "1";
       this.DOMElements.goToRegisterButton.style.opacity = "1";
 
+      if (this.resetToken) {
+        this.showPasswordResetForm();
+        return
+      }
+
       //For delaying browser autologin pop-up showing before animation is done
       setTimeout(() => {
         document.getElementById("loginUsername").focus();
@@ -86,6 +100,7 @@ export default class LoginView {
 
     const username = usernameField.value;
     const password = passwordField.value;
+    const rememberMe = document.getElementById("loginRememberMe").checked;
 
     //Check if both fields are filled
     if (username.trim() === "") {
@@ -96,7 +111,7 @@ export default class LoginView {
       return
     }
     
-    this.webSocketManager.sendLoginRequest(username, password);
+    this.webSocketManager.sendLoginRequest(username, password, rememberMe);
   }
 
   //To handle server's error message after login attempt
@@ -126,6 +141,80 @@ export default class LoginView {
     }
   }
 
+  // ----------------------------- PASSWORD RESET -----------------------------
+
+  //The server sends the email with the reset link only if there is an account with the email
+  handleForgotPasswordClick = async () => {
+    const email = prompt("Enter the email of your account");
+    if (!email || email.trim() === "") {
+      return
+    }
+
+    const reply = await this.postForm("/password/reset", { email: email.trim() });
+    if (reply.status === STRINGS.SUCCESS) {
+      this.showPasswordResetMessage("If there is an account with the email, a link to set a new password is sent to it");
+    } else {
+      this.showPasswordResetMessage(reply.error, true);
+    }
+  }
+
+  showPasswordResetForm = () => {
+    this.DOMElements.loginForm.classList.remove("d-flex");
+    this.DOMElements.loginForm.style.display = "none";
+    this.DOMElements.passwordResetForm.style.display = "flex";
+    document.getElementById("resetPassword").focus();
+  }
+
+  //Removes the used token from the address, so reloading the page shows the login form
+  hidePasswordResetForm = () => {
+    this.resetToken = null;
+    window.history.replaceState(null, "", window.location.pathname);
+    this.DOMElements.passwordResetForm.reset();
+    this.DOMElements.passwordResetForm.style.display = "none";
+    this.DOMElements.loginForm.style.display = "";
+    this.DOMElements.loginForm.classList.add("d-flex");
+  }
+
+  handlePasswordResetFormSubmit = async (event) => {
+    event.preventDefault();
+    const password = document.getElementById("resetPassword").value;
+    if (password.trim() === "") {
+      this.showPasswordResetMessage("Password missing", true);
+      return
+    }
+
+    const reply = await this.postForm("/password/reset/confirm", { token: this.resetToken, password: password });
+    if (reply.status === STRINGS.SUCCESS) {
+      this.hidePasswordResetForm();
+      this.showPasswordResetMessage("The password is changed, log in with the new password");
+      document.getElementById("loginUsername").focus();
+    } else {
+      this.showPasswordResetMessage(reply.error, true);
+    }
+  }
+
+  showPasswordResetMessage = (message, isError = false) => {
+    document.getElementById("logoutSuccessMessage").setAttribute('hidden', '');
+    const messageField = this.DOMElements.passwordResetMessage;
+    messageField.textContent = message;
+    messageField.classList.toggle("text-danger", isError);
+    messageField.removeAttribute('hidden');
+  }
+
+  //The password reset handlers reply with JSON, the rate limit rejects requests with an error page
+  postForm = async (url, fields) => {
+    try {
+      const response = await fetch(url, { method: "POST", body: new URLSearchParams(fields) });
+      if (response.status === 429) {
+        return { status: "failure", error: "Too many requests, try again later" };
+      }
+      return await response.json();
+    } catch (error) {
+      console.error("Password reset request failed:", error);
+      return { status: "failure", error: "The request failed, try again later" };
+    }
+  }
+
   // ---------------------------- NAVIGATING AWAY -----------------------------
 
   handleGoToRegisterClick = () => {
@@ -11,6 +11,7 @@ export default class OnlineUsersSidebar {
         this.webSocketManager.on("onlineUsers", this.handleOnlineUsersListReceived);
         this.webSocketManager.on("newOnlineUser", this.handleNewOnlineUserReceived);
         this.webSocketManager.on("offlineUser", this.handleOfflineUserReceived);
+        this.webSocketManager.on("blockUserReply", this.handleBlockUserReply);
 
         this.currentSessionUserData = {};
         this.currentChatRecipientData = {};
@@ -104,6 +105,14 @@ export default class OnlineUsersSidebar {
         }
     };
 
+    //A blocked user is removed from the online list
+    handleBlockUserReply = (payload) => {
+        if (payload.result !== STRINGS.SUCCESS) {
+            return
+        }
+        this.handleOfflineUserReceived(payload);
+    }
+
     handleOfflineUserReceived = (payload) => {
 
         const userID = String(payload.data.id);
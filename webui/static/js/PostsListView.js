@@ -10,6 +10,9 @@ export default class PostsListView {
         this.throttleAndDebouncedHandleScroll = throttleAndDebounce(this.handleScroll.bind(this), 300);
 
         this.webSocketManager.on("postsPortionReply", this.handleReceivedPostsPortion);
+        this.webSocketManager.on("postCreated", this.handlePostCreated);
+        this.webSocketManager.on("postEdited", this.handlePostEdited);
+        this.webSocketManager.on("postDeleted", this.handlePostDeleted);
 
         this.bottomPostID = 0;
         this.lastScrollPos = 0;
@@ -136,6 +139,26 @@ export default class PostsListView {
         }
     }
 
+    //New posts of other users are pushed by server, show them on top of the feed
+    handlePostCreated = (payload) => {
+        const postHTML = this.generatePostHTML(this.parsePostData(payload.data));
+        this.DOMElements.postsListContainer.insertAdjacentHTML("afterbegin", postHTML);
+    }
+
+    handlePostEdited = (payload) => {
+        const titleEl = this.DOMElements.postsListContainer.querySelector(`.post-title[data-id="${payload.data.id}"]`);
+        if (titleEl) {
+            titleEl.textContent = payload.data.theme;
+        }
+    }
+
+    handlePostDeleted = (payload) => {
+        const titleEl = this.DOMElements.postsListContainer.querySelector(`.post-title[data-id="${payload.data.id}"]`);
+        if (titleEl) {
+            titleEl.closest(".card").remove();
+        }
+    }
+
     //For adding to DOM, parse payload data to variables
     parsePostData = (postData) => {
         return {
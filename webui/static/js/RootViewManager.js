@@ -12,8 +12,14 @@ export default class RootViewManager {
 
     this.webSocketManager.on("currentSession", this.handleExistingSessionMessage);
     this.webSocketManager.on("loginReply", this.handleLoginReply);
+    this.webSocketManager.on("secondFactorRequired", this.handleSecondFactorRequired);
+    this.webSocketManager.on("loginSecondFactorReply", this.handleLoginReply);
     this.webSocketManager.on("registerReply", this.handleRegisterReply)
     this.webSocketManager.on("logoutReply", this.handleLogOut);
+    this.webSocketManager.on("logoutEverywhereReply", this.handleLogOut);
+    this.webSocketManager.on("sessionExpired", this.handleLogOut);
+    this.webSocketManager.on("emailVerified", this.removeVerificationBanner);
+    this.webSocketManager.on("ERROR", this.handleErrorMessage);
 
     this.views = {
       login: new LoginView(this.DOMElements, this.switchView, this.webSocketManager),
@@ -50,7 +56,8 @@ export default class RootViewManager {
     
     //Timeout to not shown loading screen for just a microsecond to user if server sends msg very quickly
     setTimeout(() => {
-      if (payload.data.user !== null) {
+      //The link from the password reset email shows the reset form even if the user is logged in
+      if (payload.data.user !== null && !this.views.login.resetToken) {
         this.handleLoginReply(payload);
       } else {
         this.views.login.showLoginForm();
@@ -58,6 +65,14 @@ export default class RootViewManager {
     }, 250)
   };
 
+  handleErrorMessage = (payload) => {
+    if (payload.result === "rateLimited") {
+      alert(payload.data.message);
+    } else {
+      console.error("Server error:", payload.data);
+    }
+  };
+
   // ------------------------------ HANDLE LOGIN ------------------------------
 
   handleLoginReply = (payload) => {
@@ -68,17 +83,55 @@ export default class RootViewManager {
     }
   };
 
+  handleSecondFactorRequired = () => {
+    const code = prompt("Enter the code from the authenticator app or a recovery code");
+    if (code) {
+      this.webSocketManager.sendLoginSecondFactorRequest(code.trim());
+    }
+  };
+
   handleSuccessfulLogin = (payload) => {
     this.storeSessionAsCookie(payload.data);
     this.views.dashboard.storeCurrentSessionUserData(payload.data.user);
     this.views.dashboard.showUsernameInNavbar();
     this.switchView(STRINGS.DASHBOARD);
+    if (payload.data.verified === false) {
+      this.showVerificationBanner();
+    }
   };
 
+  // ------------------------- EMAIL VERIFICATION -----------------------------
+
+  //Unverified users can read but cannot post or chat until they follow the link from the email
+  showVerificationBanner = () => {
+    if (document.getElementById("verificationBanner")) {
+      return
+    }
+    const banner = document.createElement("div");
+    banner.id = "verificationBanner";
+    banner.textContent = "Please confirm your email to post and chat. ";
+
+    const resendButton = document.createElement("button");
+    resendButton.textContent = "Send the email again";
+    resendButton.addEventListener("click", () => this.webSocketManager.sendVerificationEmailRequest());
+    banner.appendChild(resendButton);
+
+    document.body.prepend(banner);
+  }
+
+  removeVerificationBanner = () => {
+    document.getElementById("verificationBanner")?.remove();
+  }
+
   storeSessionAsCookie = (data) => {
     const uuid = data.user.uuid;
+    //Without "remember me" the cookie is deleted when the browser is closed
+    if (!data.remember) {
+      document.cookie = `forum_session_id=${uuid}; path=/`;
+      return
+    }
     const expiryTime = (new Date(data.user.expirySession)).toUTCString();
-    document.cookie = `forum_session_id=${uuid}; expires=${expiryTime}`;
+    document.cookie = `forum_session_id=${uuid}; expires=${expiryTime}; path=/`;
   }
 
   // ----------------------------- HANDLE LOGOUT ------------------------------
@@ -87,6 +140,7 @@ export default class RootViewManager {
   handleLogOut = (payload) => {
     if (payload.result === STRINGS.SUCCESS) {
       this.views.dashboard.removeChatRecipientData();
+      this.removeVerificationBanner();
       document.cookie = "forum_session_id=; expires=Thu, 01 Jan 1970 00:00:00 UTC; path=/;";
       this.views.login.showLoggedOutView();
       this.switchView(STRINGS.LOGIN);
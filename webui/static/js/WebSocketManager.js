@@ -7,7 +7,7 @@ export default class WebSocketManager {
 
     initialize() {
         this.socket.onopen = () => console.log("Websocket connection established");
-        this.socket.onclose = () => console.log("Websocket connection closed");
+        this.socket.onclose = (event) => console.log("Websocket connection closed", event.reason);
         this.socket.onerror = (error) => console.log("WebSocket Error", error);
         this.socket.onmessage = this.handleMessages;
     }
@@ -48,8 +48,8 @@ export default class WebSocketManager {
 
     // ------------------------- OUTGOING MESSAGES ----------------------------  
 
-    sendLoginRequest(username, password) {
-        this.socket.send(JSON.stringify({ Type: 'loginRequest', Payload: { "username": username, "password": password } }));
+    sendLoginRequest(username, password, rememberMe) {
+        this.socket.send(JSON.stringify({ Type: 'loginRequest', Payload: { "username": username, "password": password, "rememberMe": rememberMe } }));
     }
 
     sendRegisterRequest(username, firstName, lastName, birthDate, gender, email, password) {
@@ -82,6 +82,79 @@ export default class WebSocketManager {
         this.socket.send(JSON.stringify({ Type: 'postsPortionRequest', Payload: afterID }));
     }
 
+    sendEditPostRequest(postID, theme, content) {
+        this.socket.send(JSON.stringify({ Type: 'editPostRequest', Payload: { "id": postID, "theme": theme, "content": content } }));
+    }
+
+    sendDeletePostRequest(postID) {
+        this.socket.send(JSON.stringify({ Type: 'deletePostRequest', Payload: { "id": postID } }));
+    }
+
+    sendEditCommentRequest(commentID, content) {
+        this.socket.send(JSON.stringify({ Type: 'editCommentRequest', Payload: { "id": commentID, "content": content } }));
+    }
+
+    sendDeleteCommentRequest(commentID) {
+        this.socket.send(JSON.stringify({ Type: 'deleteCommentRequest', Payload: { "id": commentID } }));
+    }
+
+    //targetType is "post", "comment" or "chatMessage"
+    sendReportRequest(targetType, targetID, reason) {
+        this.socket.send(JSON.stringify({ Type: 'reportRequest', Payload: { "targetType": targetType, "targetID": targetID, "reason": reason } }));
+    }
+
+    sendBlockUserRequest(userID) {
+        this.socket.send(JSON.stringify({ Type: 'blockUserRequest', Payload: userID }));
+    }
+
+    sendUnblockUserRequest(userID) {
+        this.socket.send(JSON.stringify({ Type: 'unblockUserRequest', Payload: userID }));
+    }
+
+    sendGetProfileRequest(userID) {
+        this.socket.send(JSON.stringify({ Type: 'getProfileRequest', Payload: userID }));
+    }
+
+    sendUpdateProfileRequest(profile) {
+        this.socket.send(JSON.stringify({ Type: 'updateProfileRequest', Payload: profile }));
+    }
+
+    sendVerificationEmailRequest() {
+        this.socket.send(JSON.stringify({ Type: 'sendVerificationEmailRequest' }));
+    }
+
+    sendListSessionsRequest() {
+        this.socket.send(JSON.stringify({ Type: 'listSessionsRequest' }));
+    }
+
+    sendRevokeSessionRequest(sessionID) {
+        this.socket.send(JSON.stringify({ Type: 'revokeSessionRequest', Payload: sessionID }));
+    }
+
+    sendLogOutEverywhereRequest() {
+        this.socket.send(JSON.stringify({ Type: 'logoutEverywhereRequest' }));
+    }
+
+    sendLoginSecondFactorRequest(code) {
+        this.socket.send(JSON.stringify({ Type: 'loginSecondFactorRequest', Payload: code }));
+    }
+
+    sendSetupTwoFactorRequest() {
+        this.socket.send(JSON.stringify({ Type: 'setupTwoFactorRequest' }));
+    }
+
+    sendEnableTwoFactorRequest(code) {
+        this.socket.send(JSON.stringify({ Type: 'enableTwoFactorRequest', Payload: code }));
+    }
+
+    sendDisableTwoFactorRequest(code) {
+        this.socket.send(JSON.stringify({ Type: 'disableTwoFactorRequest', Payload: code }));
+    }
+
+    sendClosePost(postID) {
+        this.socket.send(JSON.stringify({ Type: 'closePost', Payload: postID }));
+    }
+
     sendNewCommentSubmit(date, postID, commentContent) {
         this.socket.send(JSON.stringify({ Type: 'newCommentRequest', Payload: { "date": date, "post_id": postID, "content": commentContent } }));
     }
@@ -90,15 +163,41 @@ export default class WebSocketManager {
         this.socket.send(JSON.stringify({ Type: 'openChatRequest', Payload: recipientUserID }));
     }
 
-    sendCloseChatRequest() {
-        this.socket.send(JSON.stringify({ Type: 'closeChatRequest' }));
+    //Without chatID all opened chats are closed
+    sendCloseChatRequest(chatID) {
+        this.socket.send(JSON.stringify({ Type: 'closeChatRequest', Payload: chatID }));
+    }
+
+    sendChatMessage(chatID, date, messageContent) {
+        this.socket.send(JSON.stringify({ Type: 'sendMessageToOpendChatRequest', Payload: { "chatID": chatID, "date": date, "messageContent": messageContent } }));
+    }
+
+    sendMessageRead(messageID) {
+        this.socket.send(JSON.stringify({ Type: 'messageRead', Payload: { "messageID": messageID } }));
+    }
+
+    sendEditChatMessageRequest(messageID, messageContent) {
+        this.socket.send(JSON.stringify({ Type: 'editChatMessageRequest', Payload: { "id": messageID, "messageContent": messageContent } }));
+    }
+
+    sendDeleteChatMessageRequest(messageID) {
+        this.socket.send(JSON.stringify({ Type: 'deleteChatMessageRequest', Payload: { "id": messageID } }));
+    }
+
+    //Without cursor (the cursor of the last received result) the most relevant results are requested
+    sendSearchRequest(query, cursor = { rank: 0, rowID: 0 }) {
+        this.socket.send(JSON.stringify({ Type: 'searchRequest', Payload: { "query": query, "after": cursor } }));
+    }
+
+    sendTypingStarted(chatID) {
+        this.socket.send(JSON.stringify({ Type: 'typingStarted', Payload: { "chatID": chatID } }));
     }
 
-    sendChatMessage(date, messageContent) {
-        this.socket.send(JSON.stringify({ Type: 'sendMessageToOpendChatRequest', Payload: { "date": date, "messageContent": messageContent } }));
+    sendTypingStopped(chatID) {
+        this.socket.send(JSON.stringify({ Type: 'typingStopped', Payload: { "chatID": chatID } }));
     }
 
-    sendChatPortionRequest(beforeID) {
-        this.socket.send(JSON.stringify({ Type: 'chatPortionRequest', Payload: beforeID }));
+    sendChatPortionRequest(chatID, beforeID) {
+        this.socket.send(JSON.stringify({ Type: 'chatPortionRequest', Payload: { "chatID": chatID, "beforeID": beforeID } }));
     }
 }
\ No newline at end of file
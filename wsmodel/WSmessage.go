@@ -36,6 +36,108 @@ const (
 	ChatPortionReply              = "chatPortionReply"
 	NewOnlineUser                 = "newOnlineUser"
 	OfflineUser                   = "offlineUser"
+	MessageDelivered              = "messageDelivered"
+	MessageRead                   = "messageRead"
+	CreateGroupChatRequest        = "createGroupChatRequest"
+	CreateGroupChatReply          = "createGroupChatReply"
+	GroupChatsRequest             = "groupChatsRequest"
+	GroupChatsReply               = "groupChatsReply"
+	OpenGroupChatRequest          = "openGroupChatRequest"
+	OpenGroupChatReply            = "openGroupChatReply"
+	GroupChatPortionRequest       = "groupChatPortionRequest"
+	GroupChatPortionReply         = "groupChatPortionReply"
+	SendMessageToGroupChatRequest = "sendMessageToGroupChatRequest"
+	SendMessageToGroupChatReply   = "sendMessageToGroupChatReply"
+	AddGroupChatMemberRequest     = "addGroupChatMemberRequest"
+	AddGroupChatMemberReply       = "addGroupChatMemberReply"
+	RemoveGroupChatMemberRequest  = "removeGroupChatMemberRequest"
+	RemoveGroupChatMemberReply    = "removeGroupChatMemberReply"
+	LeaveGroupChatRequest         = "leaveGroupChatRequest"
+	LeaveGroupChatReply           = "leaveGroupChatReply"
+	GroupChatUpdated              = "groupChatUpdated"
+	TypingStarted                 = "typingStarted"
+	TypingStopped                 = "typingStopped"
+	EditChatMessageRequest        = "editChatMessageRequest"
+	EditChatMessageReply          = "editChatMessageReply"
+	DeleteChatMessageRequest      = "deleteChatMessageRequest"
+	DeleteChatMessageReply        = "deleteChatMessageReply"
+	ChatMessageEdited             = "chatMessageEdited"
+	ChatMessageDeleted            = "chatMessageDeleted"
+	SearchRequest                 = "searchRequest"
+	SearchReply                   = "searchReply"
+	ReactionRequest               = "reactionRequest"
+	ReactionReply                 = "reactionReply"
+	ReactionsUpdated              = "reactionsUpdated"
+	PostCreated                   = "postCreated"
+	CommentCreated                = "commentCreated"
+	ClosePost                     = "closePost"
+	EditPostRequest               = "editPostRequest"
+	EditPostReply                 = "editPostReply"
+	DeletePostRequest             = "deletePostRequest"
+	DeletePostReply               = "deletePostReply"
+	EditCommentRequest            = "editCommentRequest"
+	EditCommentReply              = "editCommentReply"
+	DeleteCommentRequest          = "deleteCommentRequest"
+	DeleteCommentReply            = "deleteCommentReply"
+	PostEdited                    = "postEdited"
+	PostDeleted                   = "postDeleted"
+	CommentEdited                 = "commentEdited"
+	CommentDeleted                = "commentDeleted"
+	LockPostRequest               = "lockPostRequest"
+	LockPostReply                 = "lockPostReply"
+	PostLocked                    = "postLocked"
+	BanUserRequest                = "banUserRequest"
+	BanUserReply                  = "banUserReply"
+	SuspendUserRequest            = "suspendUserRequest"
+	SuspendUserReply              = "suspendUserReply"
+	MuteUserRequest               = "muteUserRequest"
+	MuteUserReply                 = "muteUserReply"
+	UserMuted                     = "userMuted"
+	SetUserRoleRequest            = "setUserRoleRequest"
+	SetUserRoleReply              = "setUserRoleReply"
+	ModerationLogRequest          = "moderationLogRequest"
+	ModerationLogReply            = "moderationLogReply"
+	BlockUserRequest              = "blockUserRequest"
+	BlockUserReply                = "blockUserReply"
+	UnblockUserRequest            = "unblockUserRequest"
+	UnblockUserReply              = "unblockUserReply"
+	BlockedUsersRequest           = "blockedUsersRequest"
+	BlockedUsersReply             = "blockedUsersReply"
+	ReportRequest                 = "reportRequest"
+	ReportReply                   = "reportReply"
+	ReportCreated                 = "reportCreated"
+	ReportsRequest                = "reportsRequest"
+	ReportsReply                  = "reportsReply"
+	ClaimReportRequest            = "claimReportRequest"
+	ClaimReportReply              = "claimReportReply"
+	ResolveReportRequest          = "resolveReportRequest"
+	ResolveReportReply            = "resolveReportReply"
+	DismissReportRequest          = "dismissReportRequest"
+	DismissReportReply            = "dismissReportReply"
+	ReportUpdated                 = "reportUpdated"
+	GetProfileRequest             = "getProfileRequest"
+	GetProfileReply               = "getProfileReply"
+	UpdateProfileRequest          = "updateProfileRequest"
+	UpdateProfileReply            = "updateProfileReply"
+	SendVerificationEmailRequest  = "sendVerificationEmailRequest"
+	SendVerificationEmailReply    = "sendVerificationEmailReply"
+	EmailVerified                 = "emailVerified"
+	ListSessionsRequest           = "listSessionsRequest"
+	ListSessionsReply             = "listSessionsReply"
+	RevokeSessionRequest          = "revokeSessionRequest"
+	RevokeSessionReply            = "revokeSessionReply"
+	LogoutEverywhereRequest       = "logoutEverywhereRequest"
+	LogoutEverywhereReply         = "logoutEverywhereReply"
+	SessionExpired                = "sessionExpired"
+	SecondFactorRequired          = "secondFactorRequired"
+	LoginSecondFactorRequest      = "loginSecondFactorRequest"
+	LoginSecondFactorReply        = "loginSecondFactorReply"
+	SetupTwoFactorRequest         = "setupTwoFactorRequest"
+	SetupTwoFactorReply           = "setupTwoFactorReply"
+	EnableTwoFactorRequest        = "enableTwoFactorRequest"
+	EnableTwoFactorReply          = "enableTwoFactorReply"
+	DisableTwoFactorRequest       = "disableTwoFactorRequest"
+	DisableTwoFactorReply         = "disableTwoFactorReply"
 )
 
 var ErrWarning = errors.New("Warning")
@@ -83,6 +185,13 @@ type Payload struct {
 	Data   any    `json:"data,omitempty"` // TODO ? would be better to get 'null' in JS
 }
 
+// RateLimited is the data of the ERROR message sent to a client, which sends the messages of a type too often
+type RateLimited struct {
+	RequestType string `json:"requestType"`
+	RetryAfter  int64  `json:"retryAfter"` // in milliseconds
+	Message     string `json:"message"`
+}
+
 func isEmpty(field string) bool {
 	return strings.TrimSpace(field) == "" || field == "undefined"
 }
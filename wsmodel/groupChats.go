@@ -0,0 +1,55 @@
+package wsmodel
+
+const MAX_GROUP_CHAT_TITLE_LENGTH = 100
+
+type GroupChat struct {
+	Title     string `json:"title"`
+	MembersID []int  `json:"membersID"`
+}
+
+func (g *GroupChat) Validate() string {
+	if isEmpty(g.Title) {
+		return "title is missing"
+	}
+	if len(g.Title) > MAX_GROUP_CHAT_TITLE_LENGTH {
+		return "title is too long"
+	}
+	seen := make(map[int]bool, len(g.MembersID))
+	for _, id := range g.MembersID {
+		if id <= 0 {
+			return "invalide member's ID"
+		}
+		if seen[id] {
+			return "member's ID is repeated"
+		}
+		seen[id] = true
+	}
+	return ""
+}
+
+type GroupChatMember struct {
+	ChatID int `json:"chatID"`
+	UserID int `json:"userID"`
+}
+
+func (m *GroupChatMember) Validate() string {
+	if m.ChatID <= 0 {
+		return "invalide chat's ID"
+	}
+	if m.UserID <= 0 {
+		return "invalide user's ID"
+	}
+	return ""
+}
+
+type ChatPortion struct {
+	ChatID   int `json:"chatID"`
+	BeforeID int `json:"beforeID"`
+}
+
+func (p *ChatPortion) Validate() string {
+	if p.ChatID <= 0 {
+		return "invalide chat's ID"
+	}
+	return ""
+}
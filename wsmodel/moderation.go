@@ -0,0 +1,130 @@
+package wsmodel
+
+import (
+	"strings"
+	"time"
+
+	"forum/model"
+)
+
+const MAX_REASON_LENGTH = 500 // maximum length of a reason of a moderator's action
+
+/*
+locking or unlocking of a post by a moderator, no new comments can be added to a locked post
+*/
+type LockedPost struct {
+	PostID int    `json:"postID"`
+	Locked bool   `json:"locked"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (p *LockedPost) Validate() string {
+	if p.PostID <= 0 {
+		return "invalide post's ID"
+	}
+	return validateReason(p.Reason)
+}
+
+/*
+banning or unbanning of a user by a moderator. A banned user cannot log in
+*/
+type BannedUser struct {
+	UserID int    `json:"userID"`
+	Banned bool   `json:"banned"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (u *BannedUser) Validate() string {
+	if u.UserID <= 0 {
+		return "invalide user's ID"
+	}
+	return validateReason(u.Reason)
+}
+
+const MAX_SANCTION_MINUTES = 365 * 24 * 60 // the longest suspension or mute is one year
+
+/*
+suspending or muting of a user by a moderator for the given number of minutes, 0 minutes lifts the sanction.
+The server fills in the time when the sanction ends
+*/
+type UserSanction struct {
+	UserID  int        `json:"userID"`
+	Minutes int        `json:"minutes"`
+	Reason  string     `json:"reason,omitempty"`
+	Until   *time.Time `json:"until,omitempty"`
+}
+
+func (u *UserSanction) Validate() string {
+	if u.UserID <= 0 {
+		return "invalide user's ID"
+	}
+	if u.Minutes < 0 || u.Minutes > MAX_SANCTION_MINUTES {
+		return "invalide duration of the sanction"
+	}
+	return validateReason(u.Reason)
+}
+
+/*
+changing of a user's role by an admin
+*/
+type UserRole struct {
+	UserID int        `json:"userID"`
+	Role   model.Role `json:"role"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+func (u *UserRole) Validate() string {
+	if u.UserID <= 0 {
+		return "invalide user's ID"
+	}
+	if !u.Role.IsValid() {
+		return "unknown role"
+	}
+	return validateReason(u.Reason)
+}
+
+func validateReason(reason string) string {
+	if len(reason) > MAX_REASON_LENGTH {
+		return "reason is too long"
+	}
+	return ""
+}
+
+/*
+a report of a user about a post, a comment or a chat message
+*/
+type Report struct {
+	TargetType string `json:"targetType"`
+	TargetID   int    `json:"targetID"`
+	Reason     string `json:"reason"`
+}
+
+func (r *Report) Validate() string {
+	if r.TargetType != model.POST && r.TargetType != model.COMMENT && r.TargetType != model.CHAT_MESSAGE {
+		return "unknown type of the reported item"
+	}
+	if r.TargetID <= 0 {
+		return "invalide ID of the reported item"
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return "reason is missing"
+	}
+	return validateReason(r.Reason)
+}
+
+/*
+a request of a moderator for a portion of the reports queue.
+Without a status the open and claimed reports are sent, BeforeID <= 0 means the latest reports
+*/
+type ReportsPortion struct {
+	Status   string `json:"status,omitempty"`
+	BeforeID int    `json:"beforeID,omitempty"`
+}
+
+func (p *ReportsPortion) Validate() string {
+	switch p.Status {
+	case "", model.REPORT_OPEN, model.REPORT_CLAIMED, model.REPORT_RESOLVED, model.REPORT_DISMISSED:
+		return ""
+	}
+	return "unknown status of reports"
+}
@@ -32,6 +32,12 @@ func PayloadToUserCredential(payload json.RawMessage) (wsmodel.UserCredentials,
 	return uC, err
 }
 
+func PayloadToProfileUpdate(payload json.RawMessage) (wsmodel.ProfileUpdate, error) {
+	var profile wsmodel.ProfileUpdate
+	err := json.Unmarshal(payload, &profile)
+	return profile, err
+}
+
 func PayloadToPost(payload json.RawMessage) (wsmodel.Post, error) {
 	var post wsmodel.Post
 	err := json.Unmarshal(payload, &post)
@@ -55,3 +61,111 @@ func PayloadToReaction(payload json.RawMessage) (wsmodel.Reaction, error) {
 	err := json.Unmarshal(payload, &react)
 	return react, err
 }
+
+func PayloadToChatMessageReceipt(payload json.RawMessage) (wsmodel.ChatMessageReceipt, error) {
+	var receipt wsmodel.ChatMessageReceipt
+	err := json.Unmarshal(payload, &receipt)
+	return receipt, err
+}
+
+func PayloadToEditedChatMessage(payload json.RawMessage) (wsmodel.EditedChatMessage, error) {
+	var message wsmodel.EditedChatMessage
+	err := json.Unmarshal(payload, &message)
+	return message, err
+}
+
+func PayloadToDeletedChatMessage(payload json.RawMessage) (wsmodel.DeletedChatMessage, error) {
+	var message wsmodel.DeletedChatMessage
+	err := json.Unmarshal(payload, &message)
+	return message, err
+}
+
+func PayloadToEditedPost(payload json.RawMessage) (wsmodel.EditedPost, error) {
+	var post wsmodel.EditedPost
+	err := json.Unmarshal(payload, &post)
+	return post, err
+}
+
+func PayloadToDeletedPost(payload json.RawMessage) (wsmodel.DeletedPost, error) {
+	var post wsmodel.DeletedPost
+	err := json.Unmarshal(payload, &post)
+	return post, err
+}
+
+func PayloadToEditedComment(payload json.RawMessage) (wsmodel.EditedComment, error) {
+	var comment wsmodel.EditedComment
+	err := json.Unmarshal(payload, &comment)
+	return comment, err
+}
+
+func PayloadToDeletedComment(payload json.RawMessage) (wsmodel.DeletedComment, error) {
+	var comment wsmodel.DeletedComment
+	err := json.Unmarshal(payload, &comment)
+	return comment, err
+}
+
+func PayloadToLockedPost(payload json.RawMessage) (wsmodel.LockedPost, error) {
+	var post wsmodel.LockedPost
+	err := json.Unmarshal(payload, &post)
+	return post, err
+}
+
+func PayloadToBannedUser(payload json.RawMessage) (wsmodel.BannedUser, error) {
+	var user wsmodel.BannedUser
+	err := json.Unmarshal(payload, &user)
+	return user, err
+}
+
+func PayloadToUserSanction(payload json.RawMessage) (wsmodel.UserSanction, error) {
+	var sanction wsmodel.UserSanction
+	err := json.Unmarshal(payload, &sanction)
+	return sanction, err
+}
+
+func PayloadToUserRole(payload json.RawMessage) (wsmodel.UserRole, error) {
+	var userRole wsmodel.UserRole
+	err := json.Unmarshal(payload, &userRole)
+	return userRole, err
+}
+
+func PayloadToReport(payload json.RawMessage) (wsmodel.Report, error) {
+	var report wsmodel.Report
+	err := json.Unmarshal(payload, &report)
+	return report, err
+}
+
+func PayloadToReportsPortion(payload json.RawMessage) (wsmodel.ReportsPortion, error) {
+	var portion wsmodel.ReportsPortion
+	err := json.Unmarshal(payload, &portion)
+	return portion, err
+}
+
+func PayloadToSearch(payload json.RawMessage) (wsmodel.Search, error) {
+	var search wsmodel.Search
+	err := json.Unmarshal(payload, &search)
+	return search, err
+}
+
+func PayloadToTyping(payload json.RawMessage) (wsmodel.Typing, error) {
+	var typing wsmodel.Typing
+	err := json.Unmarshal(payload, &typing)
+	return typing, err
+}
+
+func PayloadToGroupChat(payload json.RawMessage) (wsmodel.GroupChat, error) {
+	var groupChat wsmodel.GroupChat
+	err := json.Unmarshal(payload, &groupChat)
+	return groupChat, err
+}
+
+func PayloadToGroupChatMember(payload json.RawMessage) (wsmodel.GroupChatMember, error) {
+	var member wsmodel.GroupChatMember
+	err := json.Unmarshal(payload, &member)
+	return member, err
+}
+
+func PayloadToChatPortion(payload json.RawMessage) (wsmodel.ChatPortion, error) {
+	var portion wsmodel.ChatPortion
+	err := json.Unmarshal(payload, &portion)
+	return portion, err
+}
@@ -0,0 +1,23 @@
+package wsmodel
+
+import "forum/model"
+
+const MAX_SEARCH_QUERY_LENGTH = 200
+
+/*
+full-text search request. The next portion of results is requested with the cursor of the last received result
+*/
+type Search struct {
+	Query string             `json:"query"`
+	After model.SearchCursor `json:"after"`
+}
+
+func (s *Search) Validate() string {
+	if isEmpty(s.Query) {
+		return "search query missing"
+	}
+	if len(s.Query) > MAX_SEARCH_QUERY_LENGTH {
+		return "search query is too long"
+	}
+	return ""
+}
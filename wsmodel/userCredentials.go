@@ -12,6 +12,8 @@ type UserCredentials struct {
 	Gender    string `json:"gender,omitempty"`
 	FirstName string `json:"firstName,omitempty"`
 	LastName  string `json:"lastName,omitempty"`
+	// the session lives longer and has no idle timeout, used at login only
+	RememberMe bool `json:"rememberMe,omitempty"`
 }
 
 func (u *UserCredentials) Validate() string {
@@ -23,12 +25,11 @@ func (u *UserCredentials) Validate() string {
 	}
 
 	// check email
-	// mail.ParseAddress accepts also local domens e.g. witout .(dot)
-	address, err := mail.ParseAddress(u.Email)
-	if err != nil {
-		return "wrong email"
+	email, errmessage := validateEmail(u.Email)
+	if errmessage != "" {
+		return errmessage
 	}
-	u.Email = address.Address // in case of full address like "Barry Gibbs <bg@example.com>"
+	u.Email = email
 	// the regex allows only Internet emails, e.g. with dot-atom domain (https://www.rfc-editor.org/rfc/rfc5322.html#section-3.4)
 	// if !regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`).Match([]byte(email)) {
 	// 	return "wrong email"
@@ -53,6 +54,67 @@ func (u *UserCredentials) Validate() string {
 	return ""
 }
 
+/*
+returns the address of the email or an error message if the email is wrong
+*/
+func validateEmail(email string) (string, string) {
+	// mail.ParseAddress accepts also local domens e.g. witout .(dot)
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", "wrong email"
+	}
+	return address.Address, "" // in case of full address like "Barry Gibbs <bg@example.com>"
+}
+
+/*
+changes of the current user's profile. The password is changed only if NewPassword is given,
+the current password is required then
+*/
+type ProfileUpdate struct {
+	Email           string `json:"email"`
+	DateBirth       string `json:"dateBirth"`
+	Gender          string `json:"gender"`
+	FirstName       string `json:"firstName"`
+	LastName        string `json:"lastName"`
+	CurrentPassword string `json:"currentPassword,omitempty"`
+	NewPassword     string `json:"newPassword,omitempty"`
+}
+
+func (p *ProfileUpdate) Validate() string {
+	if isEmpty(p.Email) {
+		return "email missing"
+	}
+	email, errmessage := validateEmail(p.Email)
+	if errmessage != "" {
+		return errmessage
+	}
+	p.Email = email
+
+	if isEmpty(p.DateBirth) {
+		return "dateBirth missing"
+	}
+	if isEmpty(p.Gender) {
+		return "gender missing"
+	}
+	if isEmpty(p.FirstName) {
+		return "First name missing"
+	}
+	if isEmpty(p.LastName) {
+		return "Last name missing"
+	}
+
+	if p.NewPassword == "" {
+		return ""
+	}
+	if isEmpty(p.NewPassword) {
+		return "new password missing"
+	}
+	if isEmpty(p.CurrentPassword) {
+		return "current password missing"
+	}
+	return ""
+}
+
 type UserWithMessageDate struct {
 	ID              int    `json:"id"`
 	Name            string `json:"name"`
@@ -3,16 +3,20 @@ package wsmodel
 // TODO ?if it will not take too much work, add Data field to Post and Comment structs to get it from frontend
 // TODO ?if it will not take too much work, rename fields Text to Content or fields Content in model package to Text
 import (
+	"fmt"
 	"time"
 
 	"forum/model"
 )
 
+const MAX_ATTACHED_IMAGES = 10 // maximum number of images attached to a post, a comment or a chat message
+
 type Post struct {
 	Theme        string    `json:"theme"`
 	Content      string    `json:"content"`
 	CategoriesID []int     `json:"categoriesID"`
 	Date         time.Time `json:"date"`
+	Images       []string  `json:"images,omitempty"` // ids of uploaded images
 }
 
 func (p *Post) Validate() string {
@@ -27,6 +31,10 @@ func (p *Post) Validate() string {
 		return "Choose at least one category"
 	}
 
+	if len(p.Images) > MAX_ATTACHED_IMAGES {
+		return fmt.Sprintf("no more than %d images can be attached", MAX_ATTACHED_IMAGES)
+	}
+
 	if p.Date.Before(time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC)) {
 		return "Date is too old"
 	}
@@ -38,6 +46,7 @@ type Comment struct {
 	PostID  int       `json:"post_id"`
 	Content string    `json:"content"`
 	Date    time.Time `json:"date"`
+	Images  []string  `json:"images,omitempty"` // ids of uploaded images
 }
 
 func (c *Comment) Validate() string {
@@ -48,26 +57,187 @@ func (c *Comment) Validate() string {
 		return "invalide post's ID"
 	}
 
+	if len(c.Images) > MAX_ATTACHED_IMAGES {
+		return fmt.Sprintf("no more than %d images can be attached", MAX_ATTACHED_IMAGES)
+	}
+
 	if c.Date.Before(time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC)) {
 		return "Date is too old"
 	}
 	return ""
 }
 
+/*
+new theme and content of a post.
+A client sends ID, Theme and Content, the server fills in the date before sending the change to the clients
+*/
+type EditedPost struct {
+	ID       int       `json:"id"`
+	Theme    string    `json:"theme"`
+	Content  string    `json:"content"`
+	DateEdit time.Time `json:"dateEdit"`
+}
+
+func (p *EditedPost) Validate() string {
+	if p.ID <= 0 {
+		return "invalide post's ID"
+	}
+	if isEmpty(p.Theme) {
+		return "Post's theme missing"
+	}
+	if isEmpty(p.Content) {
+		return "Post's text missing"
+	}
+	return ""
+}
+
+/*
+deleting of a post. A client sends ID, a moderator deleting a post of another user may give a reason
+*/
+type DeletedPost struct {
+	ID     int    `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (p *DeletedPost) Validate() string {
+	if p.ID <= 0 {
+		return "invalide post's ID"
+	}
+	return validateReason(p.Reason)
+}
+
+/*
+new content of a comment.
+A client sends ID and Content, the server fills in the other fields before sending the change to the clients viewing the post
+*/
+type EditedComment struct {
+	ID       int       `json:"id"`
+	PostID   int       `json:"postID,omitempty"`
+	Content  string    `json:"content"`
+	DateEdit time.Time `json:"dateEdit"`
+}
+
+func (c *EditedComment) Validate() string {
+	if c.ID <= 0 {
+		return "invalide comment's ID"
+	}
+	if isEmpty(c.Content) {
+		return "Comment's text missing"
+	}
+	return ""
+}
+
+/*
+deleting of a comment.
+A client sends ID, a moderator deleting a comment of another user may give a reason.
+The server fills in the post's ID before sending the change to the clients viewing the post
+*/
+type DeletedComment struct {
+	ID     int    `json:"id"`
+	PostID int    `json:"postID,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (c *DeletedComment) Validate() string {
+	if c.ID <= 0 {
+		return "invalide comment's ID"
+	}
+	return validateReason(c.Reason)
+}
+
 type ChatMessage struct {
+	ID             int         `json:"id,omitempty"`
+	ChatID         int         `json:"chatID,omitempty"`
 	MessageContent string      `json:"messageContent"`
 	Author         *model.User `json:"author,omitempty"`
 	Date           time.Time   `json:"date"`
+	Images         []string    `json:"images,omitempty"` // ids of uploaded images
 }
 
 func (m *ChatMessage) Validate() string {
 	if m.Date.Before(time.Date(2023, time.September, 1, 0, 0, 0, 0, time.UTC)) {
 		return "Date is too old"
 	}
+	// a message can consist of images only
+	if isEmpty(m.MessageContent) && len(m.Images) == 0 {
+		return "text is missing"
+	}
+	if len(m.Images) > MAX_ATTACHED_IMAGES {
+		return fmt.Sprintf("no more than %d images can be attached", MAX_ATTACHED_IMAGES)
+	}
+
+	return ""
+}
+
+/*
+acknowledgement of delivery or reading of a chat message.
+A client sends only MessageID, the server fills in the other fields before notifying the message's author
+*/
+type ChatMessageReceipt struct {
+	MessageID int       `json:"messageID"`
+	ChatID    int       `json:"chatID,omitempty"`
+	UserID    int       `json:"userID,omitempty"`
+	Date      time.Time `json:"date,omitempty"`
+}
+
+func (r *ChatMessageReceipt) Validate() string {
+	if r.MessageID <= 0 {
+		return "invalide message's ID"
+	}
+	return ""
+}
+
+/*
+new content of a chat message.
+A client sends ID and MessageContent, the server fills in the other fields before sending the change to the chat members
+*/
+type EditedChatMessage struct {
+	ID             int       `json:"id"`
+	ChatID         int       `json:"chatID,omitempty"`
+	MessageContent string    `json:"messageContent"`
+	DateEdit       time.Time `json:"dateEdit"`
+}
+
+func (m *EditedChatMessage) Validate() string {
+	if m.ID <= 0 {
+		return "invalide message's ID"
+	}
 	if isEmpty(m.MessageContent) {
 		return "text is missing"
 	}
+	return ""
+}
+
+/*
+deleting of a chat message.
+A client sends only ID, the server fills in the other fields before sending the change to the chat members
+*/
+type DeletedChatMessage struct {
+	ID         int       `json:"id"`
+	ChatID     int       `json:"chatID,omitempty"`
+	DateDelete time.Time `json:"dateDelete"`
+}
+
+func (m *DeletedChatMessage) Validate() string {
+	if m.ID <= 0 {
+		return "invalide message's ID"
+	}
+	return ""
+}
+
+/*
+typing indicator in a chat.
+A client sends only ChatID, the server fills in the user before relaying the indicator to the other members of the chat
+*/
+type Typing struct {
+	ChatID int         `json:"chatID"`
+	User   *model.User `json:"user,omitempty"`
+}
 
+func (t *Typing) Validate() string {
+	if t.ChatID <= 0 {
+		return "invalide chat's ID"
+	}
 	return ""
 }
 